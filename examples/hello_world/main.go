@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/wdevore/RISCV-Meta-Assembler/src"
@@ -37,6 +38,8 @@ func run_assembler() {
 	// assembler.Print()
 
 	log.Println("Assembly done.")
+
+	fmt.Print(assembler.Listing())
 }
 
 // func test_expression() {
@@ -9,11 +9,35 @@ type IAssembler interface {
 	ReportLine(line int, message string)
 	ReportWhere(line int, where, message string)
 	ReportToken(token IToken, message string)
+	// Warnings don't stop assembly
+	ReportWarning(line int, message string)
+	ReportIn(file string, line int, message string)
+	ReportWarningIn(file string, line int, message string)
 	ErrorOccurred() bool
 	SetError(occurred bool)
 
+	// Sections collected while interpreting
+	Sections() []ISection
+	Section(name string) ISection
+	AddSection(section ISection)
+
+	// Every symbol defined by any module
+	Symbols() ISymbolTable
+
+	// Loads a module by name. Each module is loaded once.
+	Import(name string) (module IModule, err error)
+
 	// The main process
 	Run(source string) error
+	// 1 while the source is interpreted, 2 once the sections are
+	// being laid out and encoded
+	Pass() int
+	// Runs a check once the sections are laid out, for example an
+	// "assert" about the size of a section
+	AfterLayout(check func())
+
+	// Addresses and machine code of everything assembled
+	Listing() string
 
 	// Print()
 }
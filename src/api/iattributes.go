@@ -0,0 +1,58 @@
+package api
+
+type AccessType int64
+
+const (
+	// since iota starts with 0, the first value
+	// defined here will be the default
+	ACCESS_DEFAULT AccessType = iota
+	ACCESS_READ_ONLY
+	ACCESS_READ_WRITE
+)
+
+type RelaxType int64
+
+const (
+	// Relaxation follows the "Relax" setting of config.json
+	RELAX_DEFAULT RelaxType = iota
+	RELAX_ENABLED
+	RELAX_DISABLED
+)
+
+// IAttributes is the validated attribute list that prefixes a section,
+// for example "[alignTo word, global, at 0x00010000, readOnly]".
+type IAttributes interface {
+	// Alignment in bytes. Zero if "alignTo" wasn't given.
+	Alignment() int
+	Global() bool
+	// The "at" address expression or nil
+	At() IExpression
+	Access() AccessType
+	// Whether "call", "tail" and "la" may be shortened
+	Relax() RelaxType
+	// False for "noCompress", whose instructions keep their 32-bit
+	// encodings even if the ISA has C
+	Compress() bool
+}
+
+func (t AccessType) String() string {
+	switch t {
+	case ACCESS_READ_ONLY:
+		return "readOnly"
+	case ACCESS_READ_WRITE:
+		return "readWrite"
+	default:
+		return "default"
+	}
+}
+
+func (t RelaxType) String() string {
+	switch t {
+	case RELAX_ENABLED:
+		return "relax"
+	case RELAX_DISABLED:
+		return "noRelax"
+	default:
+		return "default"
+	}
+}
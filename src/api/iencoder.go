@@ -0,0 +1,22 @@
+package api
+
+type IEncoder interface {
+	// Number of bytes an instruction item assembles to. Pseudo
+	// instructions may expand into more than one word.
+	Size(section ISection, item ISectionItem) (size int, err IRuntimeError)
+	// Encodes an instruction item into machine code. For a pseudo
+	// instruction the expansion lists the base instructions it
+	// assembled to. Operands are evaluated with the section's
+	// interpreter.
+	Encode(section ISection, item ISectionItem) (bytes []byte, expansion []string, err IRuntimeError)
+	// Evaluates the initializers of a data item again now that every
+	// symbol has its address.
+	EncodeData(section ISection, item ISectionItem) (bytes []byte, err IRuntimeError)
+	// Whether an instruction item can take a shorter form at the
+	// current layout. The item is given it by SetRelaxed.
+	Relax(section ISection, item ISectionItem) bool
+	// Whether a conditional branch item's target, offset bytes away,
+	// is out of its reach at the current layout. The item is given
+	// its long form by SetLongBranch.
+	LongBranch(section ISection, item ISectionItem) (offset int, long bool)
+}
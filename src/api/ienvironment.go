@@ -4,6 +4,9 @@ type IEnvironment interface {
 	Assign(name IToken, obj interface{}) IRuntimeError
 	AssignAt(distance int, name IToken, obj interface{}) IRuntimeError
 	Define(name string, obj interface{}) IRuntimeError
+	// Defines a name that can't be assigned to afterwards
+	DefineConstant(name string, obj interface{}) IRuntimeError
+	IsConstant(name string) bool
 	Get(name IToken) (obj interface{}, err IRuntimeError)
 	GetAt(distance int, name IToken) (obj interface{}, err IRuntimeError)
 	Enclosing() IEnvironment
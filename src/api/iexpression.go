@@ -16,6 +16,10 @@ const (
 	WHILE_EXPR
 	CALL_EXPR
 	FUN_EXPR
+	GET_EXPR
+	LOCAL_LABEL_EXPR
+	RELOCATION_EXPR
+	MEMORY_EXPR
 )
 
 type IExpression interface {
@@ -42,6 +46,13 @@ type IExpression interface {
 	Callee() IExpression
 	Paren() IToken
 	Arguments() []IExpression
+
+	// Get, for example "std.printf"
+	Object() IExpression
+
+	// Memory operand, for example "12(sp)". The offset is the
+	// Expression() and may be nil.
+	Base() IExpression
 }
 
 func (e ExpressionType) String() string {
@@ -68,6 +79,14 @@ func (e ExpressionType) String() string {
 		return "CallableExpression"
 	case FUN_EXPR:
 		return "FunctionExpression"
+	case GET_EXPR:
+		return "GetExpression"
+	case LOCAL_LABEL_EXPR:
+		return "LocalLabelExpression"
+	case RELOCATION_EXPR:
+		return "RelocationExpression"
+	case MEMORY_EXPR:
+		return "MemoryExpression"
 	}
 
 	return "unknown"
@@ -39,6 +39,13 @@ type IBinaryNumberLiteral interface {
 	BinValue() string
 }
 
+// A register operand, for example "a0" which is register 10.
+type IRegisterLiteral interface {
+	ILiteral
+	RegisterValue() int
+	IsFloat() bool
+}
+
 type INilLiteral interface {
 	ILiteral
 	NilValue() string
@@ -0,0 +1,13 @@
+package api
+
+// IModule is a file loaded by an "import" statement.
+type IModule interface {
+	// The alias given by "as"
+	Name() string
+	Path() string
+	// The module's own global scope
+	Globals() IEnvironment
+	// Exports are the module's globals minus its local symbols.
+	Export(name string) (obj interface{}, ok bool)
+	Exports() map[string]interface{}
+}
@@ -3,4 +3,16 @@ package api
 type IProperties interface {
 	BinaryName() string
 	Files() []string
+	// Additional directories searched by "import"
+	SearchPaths() []string
+	// Default for the "relax" section attribute
+	Relax() bool
+	// Name of the symbol whose address gp holds, or ""
+	GlobalPointer() string
+	// Constants from the config's "Defines", as literals
+	Defines() map[string]interface{}
+	// The target ISA, for example "rv32imac"
+	ISA() string
+	// True if the ISA has the extension, for example "M" or "Zicsr"
+	HasExtension(name string) bool
 }
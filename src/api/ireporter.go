@@ -3,4 +3,8 @@ package api
 type IReporter interface {
 	ReportLine(line int, message string)
 	ReportWhere(line int, where string, message string)
+	ReportWarning(line int, message string)
+	// Report a line of the given source file
+	ReportIn(file string, line int, message string)
+	ReportWarningIn(file string, line int, message string)
 }
@@ -0,0 +1,111 @@
+package api
+
+type SectionKind int64
+
+const (
+	// since iota starts with 0, the first value
+	// defined here will be the default
+	SECTION_UNKNOWN SectionKind = iota
+	SECTION_CODE
+	SECTION_DATA
+)
+
+type ItemKind int64
+
+const (
+	ITEM_UNKNOWN ItemKind = iota
+	ITEM_INSTRUCTION
+	ITEM_LABEL
+	ITEM_DATA
+)
+
+// ISectionItem is a single entry emitted into a section while the
+// interpreter walks it, for example an instruction or a label.
+type ISectionItem interface {
+	Kind() ItemKind
+	Statement() IStatement
+	// The environment that was active when the item was emitted. Operands
+	// are evaluated against it.
+	Environment() IEnvironment
+	// The interpreter that emitted the item. It may belong to another
+	// module than the section when a module's function emits it.
+	Interpreter() IInterpreter
+	// The macro invocations the item was expanded from, innermost first
+	Invocations() []IToken
+	SetInvocations(invocations []IToken)
+
+	// Location relative to the start of the section
+	Offset() int
+	SetOffset(offset int)
+	// Size of the item in bytes
+	Size() int
+	SetSize(size int)
+	// The item's little-endian image. Labels don't have one.
+	Bytes() []byte
+	SetBytes(bytes []byte)
+	// The base instructions a pseudo instruction expanded into, or the
+	// compressed form an instruction was given
+	Expansion() []string
+	SetExpansion(expansion []string)
+	// True once relaxation has given the item its short form
+	Relaxed() bool
+	SetRelaxed(relaxed bool)
+	// True for a branch rewritten as an inverted branch over a jal
+	// because its target is out of reach
+	LongBranch() bool
+	SetLongBranch(long bool)
+	// True for an instruction held to its 32-bit encodings once it
+	// grew back from a compressed form, so the layout settles
+	Uncompressed() bool
+	SetUncompressed(uncompressed bool)
+}
+
+type ISection interface {
+	Name() IToken
+	Kind() SectionKind
+	Attributes() IAttributes
+
+	// The address given by the "at" attribute, if any.
+	Origin() (address int, ok bool)
+	SetOrigin(address int)
+	// The interpreter of the module that defined the section. Item
+	// operands are evaluated with it.
+	Interpreter() IInterpreter
+	// The address of the section's first byte
+	Address() int
+	SetAddress(address int)
+
+	Items() []ISectionItem
+	Append(item ISectionItem)
+	// Finds a label or named data element
+	Lookup(name string) ISectionItem
+
+	// Size in bytes, including alignment padding between items
+	Size() int
+	// The section's little-endian byte image
+	Image() []byte
+}
+
+func (k SectionKind) String() string {
+	switch k {
+	case SECTION_CODE:
+		return "code"
+	case SECTION_DATA:
+		return "data"
+	default:
+		return "unknown section"
+	}
+}
+
+func (k ItemKind) String() string {
+	switch k {
+	case ITEM_INSTRUCTION:
+		return "instruction"
+	case ITEM_LABEL:
+		return "label"
+	case ITEM_DATA:
+		return "data"
+	default:
+		return "unknown item"
+	}
+}
@@ -41,4 +41,24 @@ type IStatement interface {
 	// "return"
 	Keyword() IToken
 	Value() IExpression
+
+	// Sections
+	Attributes() IAttributes
+
+	// "const" blocks, "import" aliases and the fields initialized by
+	// a struct data element
+	Names() []IToken
+	// "import" module paths
+	Paths() []IToken
+
+	// Data elements
+	Global() bool
+	ElementSize() int
+	Initializers() []IExpression
+
+	// Instructions and macro invocations
+	Mnemonic() IToken
+	Operands() []IExpression
+	// Source text, for listings
+	Text() string
 }
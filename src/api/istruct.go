@@ -0,0 +1,21 @@
+package api
+
+// The layout of a "struct" declaration
+type IStruct interface {
+	Name() IToken
+	// Rounded up to the alignment so consecutive structs stay aligned
+	Size() int
+	// The largest alignment of a field
+	Alignment() int
+	Fields() []IStructField
+	Field(name string) (field IStructField, ok bool)
+}
+
+type IStructField interface {
+	Name() IToken
+	// Bytes from the start of the struct
+	Offset() int
+	Size() int
+	// The type of a nested struct field, otherwise nil
+	Struct() IStruct
+}
@@ -0,0 +1,44 @@
+package api
+
+type SymbolBinding int64
+
+const (
+	// since iota starts with 0, the first value
+	// defined here will be the default
+	BINDING_LOCAL SymbolBinding = iota
+	BINDING_GLOBAL
+)
+
+// ISymbol names a location inside a section, for example a section
+// itself or a named data element.
+type ISymbol interface {
+	// The defining token
+	Name() IToken
+	Section() ISection
+	// The item the symbol refers to or nil for the start of the section.
+	Item() ISectionItem
+	Binding() SymbolBinding
+	// Offset within the section
+	Offset() int
+	// Offset plus the section's address
+	Address() int
+}
+
+// ISymbolTable holds every symbol defined in any module, in the order
+// they were defined.
+type ISymbolTable interface {
+	Add(symbol ISymbol)
+	Symbols() []ISymbol
+	// Finds a symbol by name. A global symbol is preferred over local
+	// ones, which may be defined in more than one module.
+	Lookup(name string) (symbol ISymbol, ok bool)
+}
+
+func (b SymbolBinding) String() string {
+	switch b {
+	case BINDING_GLOBAL:
+		return "global"
+	default:
+		return "local"
+	}
+}
@@ -3,6 +3,12 @@ package api
 type IInterpreter interface {
 	Interpret(statements []IStatement) IRuntimeError
 	Globals() IEnvironment
+	// The file the statements come from, for diagnostics
+	SetSource(path string)
+	Source() string
 	ExecuteBlock(statements []IStatement, parentEnv IEnvironment) (err IRuntimeError)
 	Resolve(expression IExpression, depth int) IRuntimeError
+	// Evaluates an operand of an emitted item in the environment and
+	// location it was emitted at.
+	Evaluate(section ISection, item ISectionItem, expression IExpression) (obj interface{}, err IRuntimeError)
 }
@@ -9,4 +9,8 @@ type IVisitorExpression interface {
 	VisitAssignExpression(IExpression) (obj interface{}, err IRuntimeError)
 	VisitLogicalExpression(IExpression) (obj interface{}, err IRuntimeError)
 	VisitCallExpression(IExpression) (obj interface{}, err IRuntimeError)
+	VisitGetExpression(IExpression) (obj interface{}, err IRuntimeError)
+	VisitLocalLabelExpression(IExpression) (obj interface{}, err IRuntimeError)
+	VisitRelocationExpression(IExpression) (obj interface{}, err IRuntimeError)
+	VisitMemoryExpression(IExpression) (obj interface{}, err IRuntimeError)
 }
@@ -10,4 +10,16 @@ type IVisitorStatement interface {
 	VisitInterruptStatement(IStatement) (err IRuntimeError)
 	VisitFunctionStatement(IStatement) (err IRuntimeError)
 	VisitReturnStatement(IStatement) (err IRuntimeError)
+	VisitCodeSectionStatement(IStatement) (err IRuntimeError)
+	VisitInstructionStatement(IStatement) (err IRuntimeError)
+	VisitLabelStatement(IStatement) (err IRuntimeError)
+	VisitUseStatement(IStatement) (err IRuntimeError)
+	VisitMacroStatement(IStatement) (err IRuntimeError)
+	VisitMacroInvocationStatement(IStatement) (err IRuntimeError)
+	VisitStructStatement(IStatement) (err IRuntimeError)
+	VisitEnumStatement(IStatement) (err IRuntimeError)
+	VisitDataSectionStatement(IStatement) (err IRuntimeError)
+	VisitDataElementStatement(IStatement) (err IRuntimeError)
+	VisitConstStatement(IStatement) (err IRuntimeError)
+	VisitImportStatement(IStatement) (err IRuntimeError)
 }
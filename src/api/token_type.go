@@ -16,11 +16,13 @@ const (
 	RIGHT_BRACKET
 	COMMA
 	SEMICOLON
+	COLON
 	DOT
 	MINUS
 	PLUS
 	SLASH // forward slash "/"
 	STAR
+	PIPE // "|"
 	TRUE
 	FALSE
 	NIL
@@ -38,8 +40,10 @@ const (
 
 	// Literals.
 	IDENTIFIER
+	REGISTER
 	STRING
 	NUMBER
+	LOCAL_LABEL // "1b" or "1f"
 
 	// Keywords.
 	PRINT
@@ -60,14 +64,29 @@ const (
 	AT
 	AS
 	USE
+	MACRO
+	STRUCT
+	ENUM
+	CSR
 	READ_ONLY
+	READ_WRITE
+	RELAX
+	NO_RELAX
+	NO_COMPRESS
+	BYTES
 	BYTE
 	HALF
 	WORD
 	DATA
 	INT
+	STRING_TYPE
+	CHAR
+	FLOAT
+	DOUBLE
 	HI
 	LO
+	PCREL_HI
+	PCREL_LO
 
 	// RISC-V real instructions
 	ADD
@@ -110,6 +129,128 @@ const (
 	ECALL
 	EBREAK
 
+	// RV32M
+	MUL
+	MULH
+	MULHSU
+	MULHU
+	DIV
+	DIVU
+	REM
+	REMU
+
+	// RV32A. Each also takes an ".aq", ".rl" or ".aqrl" suffix.
+	LR_W
+	SC_W
+	AMOSWAP_W
+	AMOADD_W
+	AMOXOR_W
+	AMOAND_W
+	AMOOR_W
+	AMOMIN_W
+	AMOMAX_W
+	AMOMINU_W
+	AMOMAXU_W
+
+	// RV32F and RV32D
+	FLW
+	FSW
+	FLD
+	FSD
+	FADD_S
+	FSUB_S
+	FMUL_S
+	FDIV_S
+	FSQRT_S
+	FSGNJ_S
+	FSGNJN_S
+	FSGNJX_S
+	FMIN_S
+	FMAX_S
+	FMADD_S
+	FMSUB_S
+	FNMSUB_S
+	FNMADD_S
+	FCVT_W_S
+	FCVT_WU_S
+	FCVT_S_W
+	FCVT_S_WU
+	FEQ_S
+	FLT_S
+	FLE_S
+	FCLASS_S
+	FMV_X_W
+	FMV_W_X
+	FADD_D
+	FSUB_D
+	FMUL_D
+	FDIV_D
+	FSQRT_D
+	FSGNJ_D
+	FSGNJN_D
+	FSGNJX_D
+	FMIN_D
+	FMAX_D
+	FMADD_D
+	FMSUB_D
+	FNMSUB_D
+	FNMADD_D
+	FCVT_W_D
+	FCVT_WU_D
+	FCVT_D_W
+	FCVT_D_WU
+	FEQ_D
+	FLT_D
+	FLE_D
+	FCLASS_D
+	FCVT_S_D
+	FCVT_D_S
+
+	// Zicsr
+	CSRRW
+	CSRRS
+	CSRRC
+	CSRRWI
+	CSRRSI
+	CSRRCI
+
+	// RVC. Each is the 16-bit encoding of a base instruction.
+	C_NOP
+	C_ADDI
+	C_ADDI16SP
+	C_ADDI4SPN
+	C_LI
+	C_LUI
+	C_MV
+	C_ADD
+	C_SUB
+	C_XOR
+	C_OR
+	C_AND
+	C_ANDI
+	C_SLLI
+	C_SRLI
+	C_SRAI
+	C_LW
+	C_SW
+	C_LWSP
+	C_SWSP
+	C_FLW
+	C_FSW
+	C_FLWSP
+	C_FSWSP
+	C_FLD
+	C_FSD
+	C_FLDSP
+	C_FSDSP
+	C_J
+	C_JAL
+	C_JR
+	C_JALR
+	C_BEQZ
+	C_BNEZ
+	C_EBREAK
+
 	// RISC-V pseudo instructions
 	LA
 	NOP
@@ -137,10 +278,46 @@ const (
 	RET
 	CALL
 	TAIL
+	FMV_S
+	FNEG_S
+	FABS_S
+	FMV_D
+	FNEG_D
+	FABS_D
+	CSRR
+	CSRW
+	CSRS
+	CSRC
+	CSRWI
+	CSRSI
+	CSRCI
 
 	EOF
 )
 
+// IsInstruction returns true if the token is a real or pseudo RISC-V instruction.
+func (t TokenType) IsInstruction() bool {
+	return t >= ADD && t <= CSRCI
+}
+
+// IsAtomic returns true if the token is an RV32A instruction, which
+// takes a memory ordering suffix.
+func (t TokenType) IsAtomic() bool {
+	return t >= LR_W && t <= AMOMAXU_W
+}
+
+// IsCompressed returns true if the token is an RVC instruction, the
+// 16-bit encoding of a base instruction.
+func (t TokenType) IsCompressed() bool {
+	return t >= C_NOP && t <= C_EBREAK
+}
+
+// IsPseudo returns true if the token is a pseudo instruction that
+// expands into real instructions.
+func (t TokenType) IsPseudo() bool {
+	return t >= LA && t <= CSRCI
+}
+
 func (t TokenType) String() string {
 	switch t {
 	case UNDEFINED:
@@ -161,6 +338,8 @@ func (t TokenType) String() string {
 		return ","
 	case SEMICOLON:
 		return ";"
+	case COLON:
+		return ":"
 	case DOT:
 		return "."
 	case MINUS:
@@ -171,6 +350,8 @@ func (t TokenType) String() string {
 		return "/"
 	case STAR:
 		return "*"
+	case PIPE:
+		return "|"
 	case BANG:
 		return "!"
 	case BANG_EQUAL:
@@ -187,12 +368,16 @@ func (t TokenType) String() string {
 		return "<="
 	case PERCENT:
 		return "%"
+	case REGISTER:
+		return "register"
 	case IDENTIFIER:
 		return "identifier"
 	case STRING:
 		return "string"
 	case NUMBER:
 		return "number"
+	case LOCAL_LABEL:
+		return "local label"
 	case CONST:
 		return "const"
 	case IMPORT:
@@ -209,8 +394,26 @@ func (t TokenType) String() string {
 		return "as"
 	case USE:
 		return "use"
+	case MACRO:
+		return "macro"
+	case STRUCT:
+		return "struct"
+	case ENUM:
+		return "enum"
+	case CSR:
+		return "csr"
 	case READ_ONLY:
 		return "readOnly"
+	case READ_WRITE:
+		return "readWrite"
+	case RELAX:
+		return "relax"
+	case NO_RELAX:
+		return "noRelax"
+	case NO_COMPRESS:
+		return "noCompress"
+	case BYTES:
+		return "bytes"
 	case BYTE:
 		return "byte"
 	case HALF:
@@ -221,10 +424,22 @@ func (t TokenType) String() string {
 		return "data"
 	case INT:
 		return "int"
+	case STRING_TYPE:
+		return "string"
+	case FLOAT:
+		return "float"
+	case DOUBLE:
+		return "double"
+	case CHAR:
+		return "char"
 	case HI:
 		return "hi"
 	case LO:
 		return "lo"
+	case PCREL_HI:
+		return "pcrel_hi"
+	case PCREL_LO:
+		return "pcrel_lo"
 	case ADD:
 		return "add"
 	case SUB:
@@ -287,6 +502,10 @@ func (t TokenType) String() string {
 		return "blt"
 	case BGE:
 		return "bge"
+	case BLTU:
+		return "bltu"
+	case BGEU:
+		return "bgeu"
 	case JAL:
 		return "jal"
 	case JALR:
@@ -299,6 +518,230 @@ func (t TokenType) String() string {
 		return "ecall"
 	case EBREAK:
 		return "ebreak"
+	case MUL:
+		return "mul"
+	case MULH:
+		return "mulh"
+	case MULHSU:
+		return "mulhsu"
+	case MULHU:
+		return "mulhu"
+	case DIV:
+		return "div"
+	case DIVU:
+		return "divu"
+	case REM:
+		return "rem"
+	case REMU:
+		return "remu"
+	case LR_W:
+		return "lr.w"
+	case SC_W:
+		return "sc.w"
+	case AMOSWAP_W:
+		return "amoswap.w"
+	case AMOADD_W:
+		return "amoadd.w"
+	case AMOXOR_W:
+		return "amoxor.w"
+	case AMOAND_W:
+		return "amoand.w"
+	case AMOOR_W:
+		return "amoor.w"
+	case AMOMIN_W:
+		return "amomin.w"
+	case AMOMAX_W:
+		return "amomax.w"
+	case AMOMINU_W:
+		return "amominu.w"
+	case AMOMAXU_W:
+		return "amomaxu.w"
+	case FLW:
+		return "flw"
+	case FSW:
+		return "fsw"
+	case FLD:
+		return "fld"
+	case FSD:
+		return "fsd"
+	case FADD_S:
+		return "fadd.s"
+	case FSUB_S:
+		return "fsub.s"
+	case FMUL_S:
+		return "fmul.s"
+	case FDIV_S:
+		return "fdiv.s"
+	case FSQRT_S:
+		return "fsqrt.s"
+	case FSGNJ_S:
+		return "fsgnj.s"
+	case FSGNJN_S:
+		return "fsgnjn.s"
+	case FSGNJX_S:
+		return "fsgnjx.s"
+	case FMIN_S:
+		return "fmin.s"
+	case FMAX_S:
+		return "fmax.s"
+	case FMADD_S:
+		return "fmadd.s"
+	case FMSUB_S:
+		return "fmsub.s"
+	case FNMSUB_S:
+		return "fnmsub.s"
+	case FNMADD_S:
+		return "fnmadd.s"
+	case FCVT_W_S:
+		return "fcvt.w.s"
+	case FCVT_WU_S:
+		return "fcvt.wu.s"
+	case FCVT_S_W:
+		return "fcvt.s.w"
+	case FCVT_S_WU:
+		return "fcvt.s.wu"
+	case FEQ_S:
+		return "feq.s"
+	case FLT_S:
+		return "flt.s"
+	case FLE_S:
+		return "fle.s"
+	case FCLASS_S:
+		return "fclass.s"
+	case FMV_X_W:
+		return "fmv.x.w"
+	case FMV_W_X:
+		return "fmv.w.x"
+	case FADD_D:
+		return "fadd.d"
+	case FSUB_D:
+		return "fsub.d"
+	case FMUL_D:
+		return "fmul.d"
+	case FDIV_D:
+		return "fdiv.d"
+	case FSQRT_D:
+		return "fsqrt.d"
+	case FSGNJ_D:
+		return "fsgnj.d"
+	case FSGNJN_D:
+		return "fsgnjn.d"
+	case FSGNJX_D:
+		return "fsgnjx.d"
+	case FMIN_D:
+		return "fmin.d"
+	case FMAX_D:
+		return "fmax.d"
+	case FMADD_D:
+		return "fmadd.d"
+	case FMSUB_D:
+		return "fmsub.d"
+	case FNMSUB_D:
+		return "fnmsub.d"
+	case FNMADD_D:
+		return "fnmadd.d"
+	case FCVT_W_D:
+		return "fcvt.w.d"
+	case FCVT_WU_D:
+		return "fcvt.wu.d"
+	case FCVT_D_W:
+		return "fcvt.d.w"
+	case FCVT_D_WU:
+		return "fcvt.d.wu"
+	case FEQ_D:
+		return "feq.d"
+	case FLT_D:
+		return "flt.d"
+	case FLE_D:
+		return "fle.d"
+	case FCLASS_D:
+		return "fclass.d"
+	case FCVT_S_D:
+		return "fcvt.s.d"
+	case FCVT_D_S:
+		return "fcvt.d.s"
+	case CSRRW:
+		return "csrrw"
+	case CSRRS:
+		return "csrrs"
+	case CSRRC:
+		return "csrrc"
+	case CSRRWI:
+		return "csrrwi"
+	case CSRRSI:
+		return "csrrsi"
+	case CSRRCI:
+		return "csrrci"
+	case C_NOP:
+		return "c.nop"
+	case C_ADDI:
+		return "c.addi"
+	case C_ADDI16SP:
+		return "c.addi16sp"
+	case C_ADDI4SPN:
+		return "c.addi4spn"
+	case C_LI:
+		return "c.li"
+	case C_LUI:
+		return "c.lui"
+	case C_MV:
+		return "c.mv"
+	case C_ADD:
+		return "c.add"
+	case C_SUB:
+		return "c.sub"
+	case C_XOR:
+		return "c.xor"
+	case C_OR:
+		return "c.or"
+	case C_AND:
+		return "c.and"
+	case C_ANDI:
+		return "c.andi"
+	case C_SLLI:
+		return "c.slli"
+	case C_SRLI:
+		return "c.srli"
+	case C_SRAI:
+		return "c.srai"
+	case C_LW:
+		return "c.lw"
+	case C_SW:
+		return "c.sw"
+	case C_LWSP:
+		return "c.lwsp"
+	case C_SWSP:
+		return "c.swsp"
+	case C_FLW:
+		return "c.flw"
+	case C_FSW:
+		return "c.fsw"
+	case C_FLWSP:
+		return "c.flwsp"
+	case C_FSWSP:
+		return "c.fswsp"
+	case C_FLD:
+		return "c.fld"
+	case C_FSD:
+		return "c.fsd"
+	case C_FLDSP:
+		return "c.fldsp"
+	case C_FSDSP:
+		return "c.fsdsp"
+	case C_J:
+		return "c.j"
+	case C_JAL:
+		return "c.jal"
+	case C_JR:
+		return "c.jr"
+	case C_JALR:
+		return "c.jalr"
+	case C_BEQZ:
+		return "c.beqz"
+	case C_BNEZ:
+		return "c.bnez"
+	case C_EBREAK:
+		return "c.ebreak"
 	case LA:
 		return "la"
 	case NOP:
@@ -314,7 +757,7 @@ func (t TokenType) String() string {
 	case NEGW:
 		return "negw"
 	case SEXT:
-		return "sext"
+		return "sext.w"
 	case SEQZ:
 		return "seqz"
 	case SNEZ:
@@ -351,6 +794,32 @@ func (t TokenType) String() string {
 		return "call"
 	case TAIL:
 		return "tail"
+	case FMV_S:
+		return "fmv.s"
+	case FNEG_S:
+		return "fneg.s"
+	case FABS_S:
+		return "fabs.s"
+	case FMV_D:
+		return "fmv.d"
+	case FNEG_D:
+		return "fneg.d"
+	case FABS_D:
+		return "fabs.d"
+	case CSRR:
+		return "csrr"
+	case CSRW:
+		return "csrw"
+	case CSRS:
+		return "csrs"
+	case CSRC:
+		return "csrc"
+	case CSRWI:
+		return "csrwi"
+	case CSRSI:
+		return "csrsi"
+	case CSRCI:
+		return "csrci"
 	case EOF:
 		return "eof"
 	}
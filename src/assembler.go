@@ -9,11 +9,13 @@ import (
 	"path/filepath"
 
 	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/encoder"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/interpreter"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/parser"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/resolver"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/sections"
 )
 
 type Assembler struct {
@@ -27,13 +29,30 @@ type Assembler struct {
 	// expression  api.IExpression
 	statements  []api.IStatement
 	interpreter api.IInterpreter
+	encoder     api.IEncoder
+
+	// Sections in the order they were defined
+	sections []api.ISection
+	symbols  api.ISymbolTable
+
+	// Imported modules keyed by absolute path
+	modules map[string]api.IModule
+	// Files currently being loaded, used to detect import cycles
+	loading []string
+
+	pass int
+	// Run once the sections are laid out
+	checks []func()
 }
 
 // NewAssembler creates a new assembler for compiling assembly code
 func NewAssembler() (assembler api.IAssembler, err error) {
 	ass := new(Assembler)
 	ass.report = errors.NewReport()
-	ass.interpreter = interpreter.NewInterpreter()
+	ass.encoder = encoder.NewEncoder(ass)
+	ass.sections = []api.ISection{}
+	ass.symbols = sections.NewSymbolTable()
+	ass.modules = map[string]api.IModule{}
 
 	return ass, nil
 }
@@ -46,6 +65,7 @@ func (a *Assembler) Configure(configRelPath string) error {
 	}
 
 	a.properties = props
+	a.configRelPath = configRelPath
 
 	return nil
 }
@@ -76,6 +96,15 @@ func (a *Assembler) ReportWhere(line int, where, message string) {
 	a.SetError(true)
 }
 
+func (a *Assembler) ReportIn(file string, line int, message string) {
+	a.report.ReportIn(file, line, message)
+	a.SetError(true)
+}
+
+func (a *Assembler) ReportWarningIn(file string, line int, message string) {
+	a.report.ReportWarningIn(file, line, message)
+}
+
 func (a *Assembler) ReportToken(token api.IToken, message string) {
 	if token.Type() == api.EOF {
 		a.report.ReportWhere(token.Line(), " at end", message)
@@ -84,35 +113,416 @@ func (a *Assembler) ReportToken(token api.IToken, message string) {
 	}
 }
 
+// Reports an error about an item in the file it was emitted from,
+// followed by the macro invocations it was expanded from.
+func (a *Assembler) reportItem(item api.ISectionItem, line int, message string) {
+	a.ReportIn(item.Interpreter().Source(), line, message+invokedFrom(item))
+}
+
+func invokedFrom(item api.ISectionItem) (text string) {
+	for _, invocation := range item.Invocations() {
+		text += errors.InMacro(invocation)
+	}
+
+	return text
+}
+
+func (a *Assembler) ReportWarning(line int, message string) {
+	a.report.ReportWarning(line, message)
+}
+
+func (a *Assembler) Sections() []api.ISection {
+	return a.sections
+}
+
+func (a *Assembler) Section(name string) api.ISection {
+	for _, section := range a.sections {
+		if section.Name().Lexeme() == name {
+			return section
+		}
+	}
+
+	return nil
+}
+
+func (a *Assembler) AddSection(section api.ISection) {
+	a.sections = append(a.sections, section)
+}
+
+func (a *Assembler) Symbols() api.ISymbolTable {
+	return a.symbols
+}
+
+// Run assembles in two passes. Pass 1 interprets the source, which
+// emits every instruction and data element, then sizes them and
+// assigns each section its address. Pass 2 runs the checks waiting
+// for the layout and encodes the instructions with every symbol known.
 func (a *Assembler) Run(source string) error {
+	path, err := a.sourcePath(source)
+	if err != nil {
+		return err
+	}
+
+	// Nothing carries over from a previous run
+	a.SetError(false)
+	a.sections = []api.ISection{}
+	a.symbols = sections.NewSymbolTable()
+	a.modules = map[string]api.IModule{}
+	a.checks = nil
+	// The interpreter needs the defines of the configuration
+	a.interpreter = interpreter.NewInterpreter(a)
+
+	a.pass = 1
+	a.loading = []string{path}
+
+	a.statements, err = a.load(path, a.interpreter)
+
+	a.loading = nil
+
+	if err != nil {
+		return err
+	}
+
+	// For example "error()" reports and carries on
+	if a.ErrorOccurred() {
+		return fmt.Errorf("errors were reported")
+	}
+
+	a.pass = 2
+
+	err = a.layout()
+	if err != nil {
+		return err
+	}
+
+	checks := a.checks
+	a.checks = nil
+	for _, check := range checks {
+		check()
+	}
+
+	if a.ErrorOccurred() {
+		return fmt.Errorf("errors were reported")
+	}
+
+	return a.encode()
+}
+
+func (a *Assembler) Pass() int {
+	return a.pass
+}
+
+func (a *Assembler) AfterLayout(check func()) {
+	a.checks = append(a.checks, check)
+}
+
+// Sizes every instruction, places the sections and then relaxes
+// what it can.
+func (a *Assembler) layout() error {
+	err := a.size()
+	if err != nil {
+		return err
+	}
+
+	err = a.locate()
+	if err != nil {
+		return err
+	}
+
+	err = a.relax()
+	if err != nil {
+		return err
+	}
+
+	return a.checkOverlaps()
+}
+
+// Sizes every instruction, pseudo instructions may take more than
+// one word, and lays the code sections out again.
+func (a *Assembler) size() error {
+	failed := 0
+
+	for _, section := range a.sections {
+		if section.Kind() != api.SECTION_CODE {
+			continue
+		}
+
+		offset := 0
+		for _, item := range section.Items() {
+			item.SetOffset(offset)
+
+			if item.Kind() != api.ITEM_INSTRUCTION {
+				continue
+			}
+
+			size, err := a.encoder.Size(section, item)
+			if err != nil {
+				a.reportItem(item, err.Token().Line(), err.Message())
+				failed++
+				continue
+			}
+
+			item.SetSize(size)
+			offset += size
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d instruction(s) could not be sized", failed)
+	}
+
+	return nil
+}
+
+// Gives every section without an "at" address one, following the
+// previous section. Code must be at least word aligned, or half word
+// aligned with C.
+func (a *Assembler) locate() error {
+	next := 0
+
+	codeAlignment := 4
+	if a.properties.HasExtension("C") {
+		codeAlignment = 2
+	}
+
+	for _, section := range a.sections {
+		alignment := section.Attributes().Alignment()
+		if section.Kind() == api.SECTION_CODE && alignment < codeAlignment {
+			alignment = codeAlignment
+		}
+
+		origin, ok := section.Origin()
+		if ok {
+			if origin != sections.Align(origin, alignment) {
+				a.ReportToken(section.Name(), fmt.Sprintf("Section address %#x is not aligned to %d bytes.", origin, alignment))
+				return fmt.Errorf("section '%s' is misaligned", section.Name().Lexeme())
+			}
+			section.SetAddress(origin)
+		} else {
+			section.SetAddress(sections.Align(next, alignment))
+		}
+
+		next = section.Address() + section.Size()
+	}
+
+	return nil
+}
+
+// Reports each section that shares bytes with one placed before it,
+// once the addresses are final.
+func (a *Assembler) checkOverlaps() error {
+	overlaps := 0
+
+	for index, section := range a.sections {
+		if section.Size() == 0 {
+			continue
+		}
+
+		for _, other := range a.sections[:index] {
+			if other.Size() == 0 {
+				continue
+			}
+
+			if section.Address() < other.Address()+other.Size() && other.Address() < section.Address()+section.Size() {
+				a.ReportIn(section.Interpreter().Source(), section.Name().Line(), fmt.Sprintf(
+					"Section '%s' at %#x-%#x overlaps section '%s' at %#x-%#x.",
+					section.Name().Lexeme(), section.Address(), section.Address()+section.Size(),
+					other.Name().Lexeme(), other.Address(), other.Address()+other.Size()))
+				overlaps++
+			}
+		}
+	}
+
+	if overlaps > 0 {
+		return fmt.Errorf("%d section overlap(s)", overlaps)
+	}
+
+	return nil
+}
+
+// Gives instructions their short form where the target is in reach and
+// branches whose target is out of reach their long form. This repeats
+// until nothing changes since resizing code moves labels. An item is
+// only ever shortened once. If that later takes its target out of
+// reach it gets its long form back for good. Branches only ever grow.
+// Likewise an instruction that grows back from a compressed form keeps
+// its 32-bit encoding. So the layout settles.
+func (a *Assembler) relax() error {
+	pinned := map[api.ISectionItem]bool{}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, section := range a.sections {
+			if section.Kind() != api.SECTION_CODE {
+				continue
+			}
+
+			for _, item := range section.Items() {
+				if item.Kind() != api.ITEM_INSTRUCTION {
+					continue
+				}
+
+				// Whether an instruction compresses depends on where
+				// it and its target are
+				if size, err := a.encoder.Size(section, item); err == nil && size != item.Size() {
+					if size > item.Size() {
+						item.SetUncompressed(true)
+					}
+					changed = true
+				}
+
+				if _, long := a.encoder.LongBranch(section, item); long && !item.LongBranch() {
+					item.SetLongBranch(true)
+					changed = true
+				}
+
+				if pinned[item] || !a.relaxes(section) {
+					continue
+				}
+
+				relax := a.encoder.Relax(section, item)
+
+				if relax && !item.Relaxed() {
+					item.SetRelaxed(true)
+					changed = true
+				} else if !relax && item.Relaxed() {
+					item.SetRelaxed(false)
+					pinned[item] = true
+					changed = true
+				}
+			}
+		}
+
+		if changed {
+			err := a.size()
+			if err != nil {
+				return err
+			}
+
+			err = a.locate()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	a.warnLongBranches()
+
+	return nil
+}
+
+// Warns about the branches given their long form, with the distance to
+// the target once the layout has settled.
+func (a *Assembler) warnLongBranches() {
+	for _, section := range a.sections {
+		for _, item := range section.Items() {
+			if item.Kind() != api.ITEM_INSTRUCTION || !item.LongBranch() {
+				continue
+			}
+
+			offset, _ := a.encoder.LongBranch(section, item)
+
+			mnemonic := item.Statement().Mnemonic()
+			a.ReportWarningIn(item.Interpreter().Source(), mnemonic.Line(), fmt.Sprintf(
+				"'%s' target is %+d bytes away, out of reach of a branch. Rewritten as an inverted branch over 'jal', costing 4 bytes.",
+				mnemonic.Lexeme(), offset)+invokedFrom(item))
+		}
+	}
+}
+
+func (a *Assembler) relaxes(section api.ISection) bool {
+	if section.Kind() != api.SECTION_CODE {
+		return false
+	}
+
+	switch section.Attributes().Relax() {
+	case api.RELAX_ENABLED:
+		return true
+	case api.RELAX_DISABLED:
+		return false
+	}
+
+	return a.properties.Relax()
+}
+
+// Encodes every instruction and data element. All errors are
+// reported before giving up.
+func (a *Assembler) encode() error {
+	failed := 0
+
+	for _, section := range a.sections {
+		for _, item := range section.Items() {
+			var err api.IRuntimeError
+
+			switch item.Kind() {
+			case api.ITEM_INSTRUCTION:
+				var bytes []byte
+				var expansion []string
+				bytes, expansion, err = a.encoder.Encode(section, item)
+				if err == nil {
+					item.SetBytes(bytes)
+					item.SetExpansion(expansion)
+				}
+			case api.ITEM_DATA:
+				var bytes []byte
+				bytes, err = a.encoder.EncodeData(section, item)
+				if err == nil {
+					item.SetBytes(bytes)
+				}
+			}
+
+			if err != nil {
+				a.reportItem(item, err.Token().Line(), err.Message())
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d item(s) could not be encoded", failed)
+	}
+
+	return nil
+}
+
+// Scans, parses, resolves and interprets a file.
+func (a *Assembler) load(path string, interpreter api.IInterpreter) (statements []api.IStatement, err error) {
 	scanner := scanner.NewScanner(a)
 
-	tokens, err := scanner.Scan(source)
+	tokens, err := scanner.Scan(path)
 	if err != nil {
-		return fmt.Errorf("unexpected error occurred during scan: %v", err)
+		return nil, fmt.Errorf("unexpected error occurred during scan: %v", err)
 	}
 
 	parser := parser.NewParser(a, tokens)
 
-	a.statements, err = parser.Parse()
+	statements, err = parser.Parse()
 	if err != nil {
-		return fmt.Errorf("unexpected error occurred during parser: %v", err)
+		return nil, fmt.Errorf("unexpected error occurred during parser: %v", err)
 	}
 
-	resolver := resolver.NewResolver(a.interpreter)
+	resolver := resolver.NewResolver(interpreter)
 
-	errR := resolver.Resolve(a.statements)
+	errR := resolver.Resolve(statements)
 	if errR != nil {
-		return fmt.Errorf("unexpected error occurred during interpreting: %v", errR)
+		return nil, fmt.Errorf("unexpected error occurred during interpreting: %v", errR)
 	}
 
-	rerr := a.interpreter.Interpret(a.statements)
+	interpreter.SetSource(path)
+
+	rerr := interpreter.Interpret(statements)
 
 	if rerr != nil {
-		return fmt.Errorf("unexpected error occurred during interpreting: %v", rerr)
+		if rerr.Token() == nil {
+			return nil, fmt.Errorf("unexpected error occurred during interpreting: %v", rerr)
+		}
+		// Name the file, the line alone is ambiguous across modules
+		a.ReportIn(path, rerr.Token().Line(), rerr.Message())
+		return nil, fmt.Errorf("unexpected error occurred during interpreting %s", filepath.Base(path))
 	}
 
-	return nil
+	return statements, nil
 }
 
 // func (a *Assembler) Print() {
@@ -142,12 +552,22 @@ func (a *Assembler) loadProperties(configRelPath string) (properties api.IProper
 		return nil, err
 	}
 
-	properties = &Properties{}
-	err = json.Unmarshal(bytes, properties)
+	props := &Properties{}
+	err = json.Unmarshal(bytes, props)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = props.convertDefines()
+	if err != nil {
+		return nil, err
+	}
 
+	err = props.convertISA()
 	if err != nil {
 		return nil, err
 	}
 
-	return properties, nil
+	return props, nil
 }
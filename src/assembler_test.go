@@ -0,0 +1,85 @@
+package src_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestLayout(t *testing.T) {
+	assembler := asmtest.Assemble(t, `{}`, `
+code text {
+    j done
+    nop
+done:
+    ret
+}
+data small { byte [1, 2, 3] }
+[alignTo word] data words { word [4] }
+[alignTo bytes<16>] data block { half [5] }
+[at 0x100] code far { nop }
+`)
+
+	tests := []struct {
+		name    string
+		address int
+	}{
+		{"text", 0},
+		{"small", 12},
+		{"words", 16},
+		{"block", 32},
+		{"far", 0x100},
+	}
+
+	sections := assembler.Sections()
+	for index, test := range tests {
+		section := sections[index]
+		if section.Name().Lexeme() != test.name || section.Address() != test.address {
+			t.Errorf("got section '%s' at %#x, want '%s' at %#x", section.Name().Lexeme(), section.Address(), test.name, test.address)
+		}
+	}
+
+	// The forward reference to done is resolved in pass 2
+	want := asmtest.MachineCode(t, "0080006f", "00000013", "00008067")
+	if image := sections[0].Image(); !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+
+	symbol, ok := assembler.Symbols().Lookup("done")
+	if !ok || symbol.Address() != 8 {
+		t.Errorf("got done at %#x, want 0x8", symbol.Address())
+	}
+}
+
+func TestSectionOverlap(t *testing.T) {
+	_, report, err := asmtest.Run(t, `{}`, map[string]string{"main.S": `
+code first { for (var i = 0; i < 8; i = i + 1) { nop } }
+[at 0x10] code second { nop }
+`})
+	if err == nil {
+		t.Fatal("assembled")
+	}
+
+	if !strings.Contains(report, "Section 'second' at 0x10-0x14 overlaps section 'first' at 0x0-0x20.") {
+		t.Errorf("got report %q", report)
+	}
+}
+
+// Nothing of the first run is left for the second
+func TestRunTwice(t *testing.T) {
+	assembler := asmtest.Assemble(t, `{}`, "code text { start: nop }\ndata d { byte [1] }")
+
+	err := assembler.Run("main.S")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := len(assembler.Sections()); count != 2 {
+		t.Errorf("got %d sections, want 2", count)
+	}
+	if count := len(assembler.Symbols().Symbols()); count != 3 {
+		t.Errorf("got %d symbols, want 3", count)
+	}
+}
@@ -0,0 +1,381 @@
+package encoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// The compressed forms a base instruction may take, in order of
+// preference
+var compressions = map[api.TokenType][]api.TokenType{
+	api.ADDI:   {api.C_NOP, api.C_ADDI16SP, api.C_ADDI4SPN, api.C_LI, api.C_MV, api.C_ADDI},
+	api.ADD:    {api.C_MV, api.C_ADD},
+	api.SUB:    {api.C_SUB},
+	api.XOR:    {api.C_XOR},
+	api.OR:     {api.C_OR},
+	api.AND:    {api.C_AND},
+	api.ANDI:   {api.C_ANDI},
+	api.SLLI:   {api.C_SLLI},
+	api.SRLI:   {api.C_SRLI},
+	api.SRAI:   {api.C_SRAI},
+	api.LUI:    {api.C_LUI},
+	api.LW:     {api.C_LWSP, api.C_LW},
+	api.SW:     {api.C_SWSP, api.C_SW},
+	api.FLW:    {api.C_FLWSP, api.C_FLW},
+	api.FSW:    {api.C_FSWSP, api.C_FSW},
+	api.FLD:    {api.C_FLDSP, api.C_FLD},
+	api.FSD:    {api.C_FSDSP, api.C_FSD},
+	api.JAL:    {api.C_J, api.C_JAL},
+	api.JALR:   {api.C_JR, api.C_JALR},
+	api.BEQ:    {api.C_BEQZ},
+	api.BNE:    {api.C_BNEZ},
+	api.EBREAK: {api.C_EBREAK},
+}
+
+// Written compressed instructions and the base instruction each one
+// encodes
+var compressedForms = map[api.TokenType]expander{
+	api.C_NOP: {0, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		return []instruction{{mnemonic: api.ADDI}}, nil
+	}},
+	api.C_ADDI: inPlace(api.ADDI),
+	api.C_ADDI16SP: {1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		imm, err := e.integer(operands[0])
+		return []instruction{{mnemonic: api.ADDI, rd: sp, rs1: sp, imm: imm}}, err
+	}},
+	api.C_ADDI4SPN: compressedBase(api.ADDI, 3),
+	api.C_LI: {2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		imm, err := e.integer(operands[1])
+		return []instruction{{mnemonic: api.ADDI, rd: rd, rs1: zero, imm: imm}}, err
+	}},
+	api.C_LUI: compressedBase(api.LUI, 2),
+	api.C_MV:  unaryImmediate(api.ADDI, 0),
+	api.C_ADD: inPlace(api.ADD),
+	api.C_SUB: inPlace(api.SUB),
+	api.C_XOR: inPlace(api.XOR),
+	api.C_OR:  inPlace(api.OR),
+	api.C_AND: inPlace(api.AND),
+
+	api.C_ANDI: inPlace(api.ANDI),
+	api.C_SLLI: inPlace(api.SLLI),
+	api.C_SRLI: inPlace(api.SRLI),
+	api.C_SRAI: inPlace(api.SRAI),
+
+	api.C_LW:    compressedBase(api.LW, 2),
+	api.C_SW:    compressedBase(api.SW, 2),
+	api.C_LWSP:  compressedBase(api.LW, 2),
+	api.C_SWSP:  compressedBase(api.SW, 2),
+	api.C_FLW:   compressedBase(api.FLW, 2),
+	api.C_FSW:   compressedBase(api.FSW, 2),
+	api.C_FLWSP: compressedBase(api.FLW, 2),
+	api.C_FSWSP: compressedBase(api.FSW, 2),
+	api.C_FLD:   compressedBase(api.FLD, 2),
+	api.C_FSD:   compressedBase(api.FSD, 2),
+	api.C_FLDSP: compressedBase(api.FLD, 2),
+	api.C_FSDSP: compressedBase(api.FSD, 2),
+
+	api.C_J: {1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		offset, err := e.relative(operands[0])
+		return []instruction{{mnemonic: api.JAL, rd: zero, imm: offset}}, err
+	}},
+	api.C_JAL: jalPseudo,
+	api.C_JR: {1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rs, err := e.register(operands[0])
+		return []instruction{{mnemonic: api.JALR, rd: zero, rs1: rs}}, err
+	}},
+	api.C_JALR: jalrPseudo,
+	api.C_BEQZ: branchZero(api.BEQ, false),
+	api.C_BNEZ: branchZero(api.BNE, false),
+
+	api.C_EBREAK: compressedBase(api.EBREAK, 0),
+}
+
+// Operands as the base instruction takes them
+func compressedBase(mnemonic api.TokenType, operands int) expander {
+	return expander{operands, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		in, err := e.base(mnemonic, operands)
+		return []instruction{in}, err
+	}}
+}
+
+// rd, x => mnemonic rd, rd, x
+func inPlace(mnemonic api.TokenType) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		in := instruction{mnemonic: mnemonic, rd: rd, rs1: rd}
+		if encodings[mnemonic].format == R_TYPE {
+			in.rs2, err = e.register(operands[1])
+		} else {
+			in.imm, err = e.integer(operands[1])
+		}
+		return []instruction{in}, err
+	}}
+}
+
+// Whether the instructions of an item are compressed where they can be
+func (e *Encoder) compresses(section api.ISection, item api.ISectionItem) bool {
+	return e.assembler.Properties().HasExtension("C") && section.Attributes().Compress() && !item.Uncompressed()
+}
+
+// A written compressed instruction is held to its form. The operands
+// are checked against it when it is encoded, once targets are known.
+func (e *Encoder) compressedForm(form api.TokenType, operands []api.IExpression) (instructions []instruction, err api.IRuntimeError) {
+	err = e.needExtension("C")
+	if err != nil {
+		return nil, err
+	}
+
+	expander := compressedForms[form]
+	if len(operands) != expander.operands {
+		return nil, e.error("'%s' expects %d operands, got %d", e.mnemonic.Lexeme(), expander.operands, len(operands))
+	}
+
+	instructions, err = expander.expand(e, operands)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range instructions {
+		err = e.checkExtension(instructions[i].mnemonic)
+		if err != nil {
+			return nil, err
+		}
+		instructions[i].form = form
+	}
+
+	return instructions, nil
+}
+
+// The compressed form an instruction fits, UNDEFINED if none
+func compress(in instruction) api.TokenType {
+	for _, form := range compressions[in.mnemonic] {
+		if _, ok := packCompressed(in, form); ok {
+			return form
+		}
+	}
+
+	return api.UNDEFINED
+}
+
+// Packs an instruction into a 16-bit compressed form. ok is false if
+// its registers or immediate don't fit the form.
+func packCompressed(in instruction, form api.TokenType) (half uint32, ok bool) {
+	imm := in.imm
+
+	switch form {
+	case api.C_NOP:
+		return 0x0001, in.rd == zero && in.rs1 == zero && imm == 0
+	case api.C_ADDI16SP:
+		ok = in.rd == sp && in.rs1 == sp && imm != 0 && imm%16 == 0 && fitsSigned(imm, 10)
+		return 0x6101 | field(imm, 9, 9)<<12 | field(imm, 4, 4)<<6 | field(imm, 6, 6)<<5 | field(imm, 8, 7)<<3 | field(imm, 5, 5)<<2, ok
+	case api.C_ADDI4SPN:
+		ok = in.rs1 == sp && isPrime(in.rd) && imm > 0 && imm%4 == 0 && imm < 1<<10
+		return field(imm, 5, 4)<<11 | field(imm, 9, 6)<<7 | field(imm, 2, 2)<<6 | field(imm, 3, 3)<<5 | prime(in.rd)<<2, ok
+	case api.C_LI:
+		ok = in.rs1 == zero && in.rd != zero && fitsSigned(imm, 6)
+		return 0x4001 | packCI(in.rd, imm), ok
+	case api.C_LUI:
+		// The 20-bit immediate sign extended from its low 6 bits
+		upper := imm & 0xfffff
+		if upper >= 1<<19 {
+			upper -= 1 << 20
+		}
+		ok = in.rd != zero && in.rd != sp && upper != 0 && fitsSigned(upper, 6)
+		return 0x6001 | packCI(in.rd, upper), ok
+	case api.C_MV:
+		rs, ok := moved(in)
+		return 0x8002 | in.rd<<7 | rs<<2, ok && in.rd != zero && rs != zero
+	case api.C_ADDI:
+		ok = in.rd == in.rs1 && in.rd != zero && imm != 0 && fitsSigned(imm, 6)
+		return 0x0001 | packCI(in.rd, imm), ok
+	case api.C_ADD:
+		rs, ok := accumulated(in, true)
+		return 0x9002 | in.rd<<7 | rs<<2, ok && in.rd != zero && rs != zero
+	case api.C_SUB, api.C_XOR, api.C_OR, api.C_AND:
+		rs, ok := accumulated(in, form != api.C_SUB)
+		funct2 := uint32(form - api.C_SUB)
+		return 0x8c01 | prime(in.rd)<<7 | funct2<<5 | prime(rs)<<2, ok && isPrime(in.rd) && isPrime(rs)
+	case api.C_ANDI:
+		ok = in.rd == in.rs1 && isPrime(in.rd) && fitsSigned(imm, 6)
+		return 0x8801 | field(imm, 5, 5)<<12 | prime(in.rd)<<7 | field(imm, 4, 0)<<2, ok
+	case api.C_SLLI:
+		ok = in.rd == in.rs1 && in.rd != zero && imm > 0 && imm < 1<<5
+		return 0x0002 | in.rd<<7 | field(imm, 4, 0)<<2, ok
+	case api.C_SRLI, api.C_SRAI:
+		ok = in.rd == in.rs1 && isPrime(in.rd) && imm > 0 && imm < 1<<5
+		funct2 := uint32(form - api.C_SRLI)
+		return 0x8001 | funct2<<10 | prime(in.rd)<<7 | field(imm, 4, 0)<<2, ok
+
+	case api.C_LWSP, api.C_FLWSP:
+		ok = in.rs1 == sp && imm%4 == 0 && imm >= 0 && imm < 1<<8 && (form == api.C_FLWSP || in.rd != zero)
+		half = 0x4002
+		if form == api.C_FLWSP {
+			half = 0x6002
+		}
+		return half | field(imm, 5, 5)<<12 | in.rd<<7 | field(imm, 4, 2)<<4 | field(imm, 7, 6)<<2, ok
+	case api.C_FLDSP:
+		ok = in.rs1 == sp && imm%8 == 0 && imm >= 0 && imm < 1<<9
+		return 0x2002 | field(imm, 5, 5)<<12 | in.rd<<7 | field(imm, 4, 3)<<5 | field(imm, 8, 6)<<2, ok
+	case api.C_SWSP, api.C_FSWSP:
+		ok = in.rs1 == sp && imm%4 == 0 && imm >= 0 && imm < 1<<8
+		half = 0xc002
+		if form == api.C_FSWSP {
+			half = 0xe002
+		}
+		return half | field(imm, 5, 2)<<9 | field(imm, 7, 6)<<7 | in.rs2<<2, ok
+	case api.C_FSDSP:
+		ok = in.rs1 == sp && imm%8 == 0 && imm >= 0 && imm < 1<<9
+		return 0xa002 | field(imm, 5, 3)<<10 | field(imm, 8, 6)<<7 | in.rs2<<2, ok
+
+	case api.C_LW, api.C_FLW:
+		ok = isPrime(in.rd) && isPrime(in.rs1) && imm%4 == 0 && imm >= 0 && imm < 1<<7
+		half = 0x4000
+		if form == api.C_FLW {
+			half = 0x6000
+		}
+		return half | packCL(in.rs1, in.rd, field(imm, 2, 2)<<1|field(imm, 6, 6), imm), ok
+	case api.C_SW, api.C_FSW:
+		ok = isPrime(in.rs2) && isPrime(in.rs1) && imm%4 == 0 && imm >= 0 && imm < 1<<7
+		half = 0xc000
+		if form == api.C_FSW {
+			half = 0xe000
+		}
+		return half | packCL(in.rs1, in.rs2, field(imm, 2, 2)<<1|field(imm, 6, 6), imm), ok
+	case api.C_FLD:
+		ok = isPrime(in.rd) && isPrime(in.rs1) && imm%8 == 0 && imm >= 0 && imm < 1<<8
+		return 0x2000 | packCL(in.rs1, in.rd, field(imm, 7, 6), imm), ok
+	case api.C_FSD:
+		ok = isPrime(in.rs2) && isPrime(in.rs1) && imm%8 == 0 && imm >= 0 && imm < 1<<8
+		return 0xa000 | packCL(in.rs1, in.rs2, field(imm, 7, 6), imm), ok
+
+	case api.C_J, api.C_JAL:
+		link := uint32(zero)
+		half = 0xa001
+		if form == api.C_JAL {
+			link, half = ra, 0x2001
+		}
+		ok = in.rd == link && imm%2 == 0 && fitsSigned(imm, 12)
+		return half | field(imm, 11, 11)<<12 | field(imm, 4, 4)<<11 | field(imm, 9, 8)<<9 | field(imm, 10, 10)<<8 |
+			field(imm, 6, 6)<<7 | field(imm, 7, 7)<<6 | field(imm, 3, 1)<<3 | field(imm, 5, 5)<<2, ok
+	case api.C_JR, api.C_JALR:
+		link := uint32(zero)
+		half = 0x8002
+		if form == api.C_JALR {
+			link, half = ra, 0x9002
+		}
+		return half | in.rs1<<7, in.rd == link && in.rs1 != zero && imm == 0
+	case api.C_BEQZ, api.C_BNEZ:
+		ok = in.rs2 == zero && isPrime(in.rs1) && imm%2 == 0 && fitsSigned(imm, 9)
+		half = 0xc001
+		if form == api.C_BNEZ {
+			half = 0xe001
+		}
+		return half | field(imm, 8, 8)<<12 | field(imm, 4, 3)<<10 | prime(in.rs1)<<7 |
+			field(imm, 7, 6)<<5 | field(imm, 2, 1)<<3 | field(imm, 5, 5)<<2, ok
+
+	case api.C_EBREAK:
+		return 0x9002, true
+	}
+
+	return 0, false
+}
+
+// rd, imm[5:0] split over bit 12 and bits 6:2
+func packCI(rd uint32, imm int) uint32 {
+	return field(imm, 5, 5)<<12 | rd<<7 | field(imm, 4, 0)<<2
+}
+
+// The loads and stores on x8-x15. offset[5:3] is at bits 12:10, the
+// remaining two offset bits at 6:5.
+func packCL(rs1, r uint32, low uint32, imm int) uint32 {
+	return field(imm, 5, 3)<<10 | prime(rs1)<<7 | low<<5 | prime(r)<<2
+}
+
+// The source register of a move, "addi rd, rs, 0" or "add rd, zero, rs"
+func moved(in instruction) (rs uint32, ok bool) {
+	switch in.mnemonic {
+	case api.ADDI:
+		return in.rs1, in.imm == 0
+	case api.ADD:
+		return in.rs2, in.rs1 == zero
+	}
+
+	return 0, false
+}
+
+// The second source of "rd = rd op rs". It may come first if the
+// operation commutes.
+func accumulated(in instruction, commutes bool) (rs uint32, ok bool) {
+	switch {
+	case in.rd == in.rs1:
+		return in.rs2, true
+	case commutes && in.rd == in.rs2:
+		return in.rs1, true
+	}
+
+	return 0, false
+}
+
+// The registers x8-x15, which the 3-bit register fields address
+func isPrime(register uint32) bool {
+	return register >= 8 && register <= 15
+}
+
+func prime(register uint32) uint32 {
+	return (register - 8) & 0x7
+}
+
+// Bits hi down to lo of a value
+func field(value int, hi, lo uint) uint32 {
+	return uint32(value>>lo) & (1<<(hi-lo+1) - 1)
+}
+
+func fitsSigned(value int, bits uint) bool {
+	return value >= -(1<<(bits-1)) && value < 1<<(bits-1)
+}
+
+// The instruction as its compressed form is written
+func (in instruction) compressedString() string {
+	name := in.form.String()
+	rd, rs1 := abiNames[in.rd], abiNames[in.rs1]
+
+	switch in.form {
+	case api.C_NOP, api.C_EBREAK:
+		return name
+	case api.C_ADDI16SP:
+		return fmt.Sprintf("%s %d", name, in.imm)
+	case api.C_ADDI4SPN:
+		return fmt.Sprintf("%s %s, sp, %d", name, rd, in.imm)
+	case api.C_LI, api.C_ADDI, api.C_ANDI, api.C_SLLI, api.C_SRLI, api.C_SRAI:
+		return fmt.Sprintf("%s %s, %d", name, rd, in.imm)
+	case api.C_LUI:
+		return fmt.Sprintf("%s %s, %#x", name, rd, in.imm&0xfffff)
+	case api.C_MV:
+		rs, _ := moved(in)
+		return fmt.Sprintf("%s %s, %s", name, rd, abiNames[rs])
+	case api.C_ADD, api.C_SUB, api.C_XOR, api.C_OR, api.C_AND:
+		rs, _ := accumulated(in, true)
+		return fmt.Sprintf("%s %s, %s", name, rd, abiNames[rs])
+	case api.C_J, api.C_JAL:
+		return fmt.Sprintf("%s .%+d", name, in.imm)
+	case api.C_JR, api.C_JALR:
+		return fmt.Sprintf("%s %s", name, rs1)
+	case api.C_BEQZ, api.C_BNEZ:
+		return fmt.Sprintf("%s %s, .%+d", name, rs1, in.imm)
+	}
+
+	// Loads and stores are written like their base instruction
+	base := in
+	base.form = api.UNDEFINED
+
+	return name + strings.TrimPrefix(base.String(), in.mnemonic.String())
+}
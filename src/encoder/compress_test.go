@@ -0,0 +1,43 @@
+package encoder_test
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Source of n nops, each compressed to c.nop
+func nops(n int) string {
+	return fmt.Sprintf("for (var i = 0; i < %d; i = i + 1) { nop }\n", n)
+}
+
+// The listing codes of the n c.nops
+func cnops(n int, codes ...string) []string {
+	listing := []string{}
+	for i := 0; i < n; i++ {
+		listing = append(listing, "0001")
+	}
+
+	return append(listing, codes...)
+}
+
+func TestCompressedOffsets(t *testing.T) {
+	checkEncodings(t, "", []encoding{
+		{"rv32imafdc", "top: beqz a0, top", []string{"c101"}},
+		{"rv32imafdc", "bnez a1, next\nnext:", []string{"e189"}},
+		{"rv32imafdc", "top: j top", []string{"a001"}},
+		{"rv32imafdc", "top:\n" + nops(27) + "j top", cnops(27, "b7e9")},
+
+		// c.beqz reaches -256, c.j -2048
+		{"rv32imafdc", "top:\n" + nops(128) + "beqz a0, top", cnops(128, "d101")},
+		{"rv32imafdc", "top:\n" + nops(129) + "beqz a0, top", cnops(129, "ee050fe3")},
+		{"rv32imafdc", "top:\n" + nops(1024) + "j top", cnops(1024, "b001")},
+		{"rv32imafdc", "top:\n" + nops(1025) + "j top", cnops(1025, "ffeff06f")},
+	})
+}
+
+func TestNoCompress(t *testing.T) {
+	checkEncodings(t, "[noCompress] ", []encoding{
+		{"rv32imafdc", "addi sp, sp, -16", []string{"ff010113"}},
+		{"rv32imafdc", "ret", []string{"00008067"}},
+	})
+}
@@ -0,0 +1,645 @@
+package encoder
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/sections"
+)
+
+// Encoder expands instructions into base instructions and packs
+// them into machine words using the format tables.
+type Encoder struct {
+	assembler api.IAssembler
+
+	// The item being encoded. The mnemonic is the data type keyword
+	// of a data item.
+	section  api.ISection
+	item     api.ISectionItem
+	mnemonic api.IToken
+	// Address of the item
+	pc int
+}
+
+func NewEncoder(assembler api.IAssembler) api.IEncoder {
+	o := new(Encoder)
+	o.assembler = assembler
+	return o
+}
+
+func (e *Encoder) Size(section api.ISection, item api.ISectionItem) (size int, err api.IRuntimeError) {
+	instructions, err := e.expand(section, item)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, in := range instructions {
+		size += in.size()
+	}
+
+	return size, nil
+}
+
+// The expansion is only given for pseudo, relaxed, long branch and
+// compressed instructions. A written compressed instruction is its own
+// expansion.
+func (e *Encoder) Encode(section api.ISection, item api.ISectionItem) (bytes []byte, expansion []string, err api.IRuntimeError) {
+	instructions, err := e.expand(section, item)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pseudo := item.Relaxed() || item.LongBranch() || e.pseudo(e.mnemonic.Type(), item.Statement().Operands()) != nil
+
+	for _, in := range instructions {
+		if in.form != api.UNDEFINED && !e.mnemonic.Type().IsCompressed() {
+			pseudo = true
+		}
+	}
+
+	for _, in := range instructions {
+		word, err := e.pack(in)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		bytes = append(bytes, sections.LittleEndian(int(word), in.size())...)
+		if pseudo {
+			expansion = append(expansion, in.String())
+		}
+	}
+
+	return bytes, expansion, nil
+}
+
+func (e *Encoder) EncodeData(section api.ISection, item api.ISectionItem) (bytes []byte, err api.IRuntimeError) {
+	e.section = section
+	e.item = item
+	e.mnemonic = item.Statement().Keyword()
+
+	statement := item.Statement()
+	size := statement.ElementSize()
+
+	if statement.Expression() != nil {
+		return e.encodeStruct(item)
+	}
+
+	if e.mnemonic.Type() == api.STRING_TYPE || len(statement.Initializers()) == 0 {
+		return item.Bytes(), nil
+	}
+
+	if e.mnemonic.Type() == api.FLOAT || e.mnemonic.Type() == api.DOUBLE {
+		return e.encodeFloats(statement)
+	}
+
+	for _, initializer := range statement.Initializers() {
+		value, err := e.integer(initializer)
+		if err != nil {
+			return nil, err
+		}
+
+		if !sections.Fits(value, size) {
+			return nil, e.error("Value %#x does not fit in '%s'.", value, e.mnemonic.Lexeme())
+		}
+
+		bytes = append(bytes, sections.LittleEndian(value, size)...)
+	}
+
+	return bytes, nil
+}
+
+// IEEE-754 bit patterns, single precision for "float" and double
+// for "double". Integers convert.
+func (e *Encoder) encodeFloats(statement api.IStatement) (bytes []byte, err api.IRuntimeError) {
+	for _, initializer := range statement.Initializers() {
+		obj, err := e.evaluate(initializer)
+		if err != nil {
+			return nil, err
+		}
+
+		var value float64
+
+		if number, ok := obj.(api.INumberLiteral); ok {
+			value = number.NumValue()
+		} else if integer, ok := literals.IntegerOf(obj); ok {
+			value = float64(integer)
+		} else {
+			return nil, e.error("expected number, got %s", e.describe(initializer, obj))
+		}
+
+		if e.mnemonic.Type() == api.FLOAT {
+			bytes = append(bytes, sections.LittleEndian(int(math.Float32bits(float32(value))), 4)...)
+		} else {
+			// In words, an int may only hold 32 bits
+			bits := math.Float64bits(value)
+			bytes = append(bytes, sections.LittleEndian(int(uint32(bits)), 4)...)
+			bytes = append(bytes, sections.LittleEndian(int(uint32(bits>>32)), 4)...)
+		}
+	}
+
+	return bytes, nil
+}
+
+// Places each value of a struct element at its field's offset. The
+// fields were checked when the element was laid out.
+func (e *Encoder) encodeStruct(item api.ISectionItem) (bytes []byte, err api.IRuntimeError) {
+	statement := item.Statement()
+
+	obj, err := e.evaluate(statement.Expression())
+	if err != nil {
+		return nil, err
+	}
+
+	layout := obj.(api.IStruct)
+	bytes = make([]byte, layout.Size())
+
+	for f, name := range statement.Names() {
+		field, _ := layout.Field(name.Lexeme())
+
+		value, err := e.integer(statement.Initializers()[f])
+		if err != nil {
+			return nil, err
+		}
+
+		if !sections.Fits(value, field.Size()) {
+			return nil, e.error("Value %#x does not fit in field '%s'.", value, name.Lexeme())
+		}
+
+		copy(bytes[field.Offset():], sections.LittleEndian(value, field.Size()))
+	}
+
+	return bytes, nil
+}
+
+// Evaluates the operands of an item into the base instructions it
+// assembles to. With C each one takes the compressed form it fits, if
+// any.
+func (e *Encoder) expand(section api.ISection, item api.ISectionItem) (instructions []instruction, err api.IRuntimeError) {
+	instructions, err = e.expandItem(section, item)
+	if err != nil {
+		return nil, err
+	}
+
+	if !item.LongBranch() || !isBranch(instructions) {
+		if e.compresses(section, item) {
+			for i := range instructions {
+				if instructions[i].form == api.UNDEFINED {
+					instructions[i].form = compress(instructions[i])
+				}
+			}
+		}
+		return instructions, nil
+	}
+
+	// Skip over the jal, which is 4 bytes further on than the branch
+	branch := instructions[0]
+	return []instruction{
+		{mnemonic: inverted[branch.mnemonic], rs1: branch.rs1, rs2: branch.rs2, imm: 8},
+		{mnemonic: api.JAL, rd: zero, imm: branch.imm - 4},
+	}, nil
+}
+
+func (e *Encoder) LongBranch(section api.ISection, item api.ISectionItem) (offset int, long bool) {
+	instructions, err := e.expandItem(section, item)
+	// A written "c.beqz" stays one
+	if err != nil || !isBranch(instructions) || instructions[0].form != api.UNDEFINED {
+		return 0, false
+	}
+
+	offset = instructions[0].imm
+
+	return offset, e.checkSigned(offset, 13, "") != nil
+}
+
+// The conditional branch with the opposite outcome
+var inverted = map[api.TokenType]api.TokenType{
+	api.BEQ:  api.BNE,
+	api.BNE:  api.BEQ,
+	api.BLT:  api.BGE,
+	api.BGE:  api.BLT,
+	api.BLTU: api.BGEU,
+	api.BGEU: api.BLTU,
+}
+
+func isBranch(instructions []instruction) bool {
+	return len(instructions) == 1 && encodings[instructions[0].mnemonic].format == B_TYPE
+}
+
+func (e *Encoder) expandItem(section api.ISection, item api.ISectionItem) (instructions []instruction, err api.IRuntimeError) {
+	e.begin(section, item)
+
+	mnemonic := e.mnemonic.Type()
+	operands := item.Statement().Operands()
+
+	if item.Relaxed() {
+		switch mnemonic {
+		case api.LUI:
+			// The addi of the pair does it all
+			return nil, nil
+		case api.ADDI:
+			rd, err := e.register(operands[0])
+			if err != nil {
+				return nil, err
+			}
+			return e.gpRelative(rd, operands[2].Expression())
+		}
+	}
+
+	switch mnemonic {
+	case api.NEGW, api.SEXT:
+		// The word forms only exist on RV64
+		return nil, e.error("'%s' is RV64 only", e.mnemonic.Lexeme())
+	}
+
+	if mnemonic.IsCompressed() {
+		return e.compressedForm(mnemonic, operands)
+	}
+
+	if expander := e.pseudo(mnemonic, operands); expander != nil {
+		if len(operands) != expander.operands {
+			return nil, e.error("'%s' expects %d operands, got %d", e.mnemonic.Lexeme(), expander.operands, len(operands))
+		}
+		instructions, err = expander.expand(e, operands)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, in := range instructions {
+			err = e.checkExtension(in.mnemonic)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return instructions, nil
+	}
+
+	in, err := e.base(mnemonic, operands)
+	if err != nil {
+		return nil, err
+	}
+
+	return []instruction{in}, nil
+}
+
+// Instructions of an extension need it in the configured ISA
+func (e *Encoder) checkExtension(mnemonic api.TokenType) api.IRuntimeError {
+	return e.needExtension(encodings[mnemonic].extension)
+}
+
+func (e *Encoder) needExtension(extension string) api.IRuntimeError {
+	if extension != "" && !e.assembler.Properties().HasExtension(extension) {
+		return e.error("'%s' needs the %s extension, which ISA '%s' doesn't have", e.mnemonic.Lexeme(), extension, e.assembler.Properties().ISA())
+	}
+
+	return nil
+}
+
+func (e *Encoder) begin(section api.ISection, item api.ISectionItem) {
+	e.section = section
+	e.item = item
+	e.mnemonic = item.Statement().Mnemonic()
+	e.pc = section.Address() + item.Offset()
+}
+
+func (e *Encoder) base(mnemonic api.TokenType, operands []api.IExpression) (in instruction, err api.IRuntimeError) {
+	en, ok := encodings[mnemonic]
+	if !ok {
+		return in, e.error("'%s' is not a base instruction", e.mnemonic.Lexeme())
+	}
+
+	err = e.checkExtension(mnemonic)
+	if err != nil {
+		return in, err
+	}
+
+	count := en.operandCount()
+
+	if len(operands) != count && !(en.rounding && len(operands) == count+1) {
+		return in, e.error("'%s' expects %d operands, got %d", e.mnemonic.Lexeme(), count, len(operands))
+	}
+
+	in.mnemonic = mnemonic
+
+	if en.rounding {
+		in.rm = en.funct3
+		if len(operands) > count {
+			in.rm, err = e.roundingMode(operands[count])
+			if err != nil {
+				return in, err
+			}
+		}
+	}
+
+	switch en.format {
+	case R_TYPE:
+		in.rd, in.rs1, in.rs2, err = e.registers3(operands[0], operands[1], operands[2])
+	case I_TYPE, SHIFT_TYPE:
+		in.rd, in.rs1, err = e.registers2(operands[0], operands[1])
+		if err == nil {
+			in.imm, err = e.integer(operands[2])
+		}
+	case LOAD_TYPE:
+		in.rd, err = e.register(operands[0])
+		if err == nil {
+			in.imm, in.rs1, err = e.memory(operands[1])
+		}
+	case S_TYPE:
+		in.rs2, err = e.register(operands[0])
+		if err == nil {
+			in.imm, in.rs1, err = e.memory(operands[1])
+		}
+	case B_TYPE:
+		in.rs1, in.rs2, err = e.registers2(operands[0], operands[1])
+		if err == nil {
+			in.imm, err = e.relative(operands[2])
+		}
+	case U_TYPE:
+		in.rd, err = e.register(operands[0])
+		if err == nil {
+			in.imm, err = e.integer(operands[1])
+		}
+	case J_TYPE:
+		in.rd, err = e.register(operands[0])
+		if err == nil {
+			in.imm, err = e.relative(operands[1])
+		}
+	case LR_TYPE:
+		in.aqrl = scanner.Ordering(e.mnemonic.Lexeme())
+		in.rd, err = e.register(operands[0])
+		if err == nil {
+			in.rs1, err = e.address(operands[1])
+		}
+	case AMO_TYPE:
+		in.aqrl = scanner.Ordering(e.mnemonic.Lexeme())
+		in.rd, in.rs2, err = e.registers2(operands[0], operands[1])
+		if err == nil {
+			in.rs1, err = e.address(operands[2])
+		}
+	case FP_TYPE:
+		fields := []*uint32{&in.rd, &in.rs1, &in.rs2}
+		in.rs2 = en.rs2
+		for r, class := range en.registers {
+			*fields[r], err = e.registerOf(operands[r], class == 'f')
+			if err != nil {
+				break
+			}
+		}
+	case FP4_TYPE:
+		in.rd, err = e.floatRegister(operands[0])
+		if err == nil {
+			in.rs1, err = e.floatRegister(operands[1])
+		}
+		if err == nil {
+			in.rs2, err = e.floatRegister(operands[2])
+		}
+		if err == nil {
+			in.rs3, err = e.floatRegister(operands[3])
+		}
+	case FLOAD_TYPE:
+		in.rd, err = e.floatRegister(operands[0])
+		if err == nil {
+			in.imm, in.rs1, err = e.memory(operands[1])
+		}
+	case FSTORE_TYPE:
+		in.rs2, err = e.floatRegister(operands[0])
+		if err == nil {
+			in.imm, in.rs1, err = e.memory(operands[1])
+		}
+	case CSR_TYPE:
+		in.rd, err = e.register(operands[0])
+		if err == nil {
+			in.csr, err = e.csr(operands[1])
+		}
+		if err == nil {
+			in.rs1, err = e.register(operands[2])
+		}
+	case CSRI_TYPE:
+		in.rd, err = e.register(operands[0])
+		if err == nil {
+			in.csr, err = e.csr(operands[1])
+		}
+		if err == nil {
+			in.imm, err = e.integer(operands[2])
+		}
+	}
+
+	return in, err
+}
+
+// ------------------------------------------------------------
+// Operands
+// ------------------------------------------------------------
+func (e *Encoder) register(operand api.IExpression) (number uint32, err api.IRuntimeError) {
+	return e.registerOf(operand, false)
+}
+
+func (e *Encoder) floatRegister(operand api.IExpression) (number uint32, err api.IRuntimeError) {
+	return e.registerOf(operand, true)
+}
+
+func (e *Encoder) registerOf(operand api.IExpression, float bool) (number uint32, err api.IRuntimeError) {
+	kind := "register"
+	if float {
+		kind = "float register"
+	}
+
+	if operand.Type() == api.MEMORY_EXPR {
+		return 0, e.error("expected %s, got memory operand", kind)
+	}
+
+	obj, err := e.evaluate(operand)
+	if err != nil {
+		return 0, err
+	}
+
+	register, isRegister := obj.(api.IRegisterLiteral)
+	if !isRegister || register.IsFloat() != float {
+		return 0, e.error("expected %s, got %s", kind, e.describe(operand, obj))
+	}
+
+	return uint32(register.RegisterValue()), nil
+}
+
+// Rounding modes by name, as encoded in funct3
+var roundingModes = map[string]uint32{
+	"rne": 0, "rtz": 1, "rdn": 2, "rup": 3, "rmm": 4, "dyn": 7,
+}
+
+// The optional last operand of a float operation. It is a name rather
+// than a value so the modes don't have to be reserved words.
+func (e *Encoder) roundingMode(operand api.IExpression) (rm uint32, err api.IRuntimeError) {
+	if operand.Type() == api.VAR_EXPR {
+		if rm, ok := roundingModes[operand.Name().Lexeme()]; ok {
+			return rm, nil
+		}
+	}
+
+	return 0, e.error("expected rounding mode 'rne', 'rtz', 'rdn', 'rup', 'rmm' or 'dyn'")
+}
+
+// A CSR is a number, for example a constant declared with "csr", or
+// one of the standard names. Like the rounding modes those aren't
+// reserved words, so a name the program defines comes first.
+func (e *Encoder) csr(operand api.IExpression) (number int, err api.IRuntimeError) {
+	if operand.Type() == api.VAR_EXPR && e.scopeOf(operand.Name().Lexeme()) == nil {
+		if number, ok := scanner.CSRs[operand.Name().Lexeme()]; ok {
+			return number, nil
+		}
+	}
+
+	return e.integer(operand)
+}
+
+// A memory operand "offset(base)". The offset defaults to 0.
+func (e *Encoder) memory(operand api.IExpression) (offset int, base uint32, err api.IRuntimeError) {
+	if operand.Type() != api.MEMORY_EXPR {
+		obj, err := e.evaluate(operand)
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, 0, e.error("expected memory operand 'offset(register)', got %s", e.describe(operand, obj))
+	}
+
+	if operand.Expression() != nil {
+		offset, err = e.integer(operand.Expression())
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	base, err = e.register(operand.Base())
+
+	return offset, base, err
+}
+
+// The "(rs1)" operand of the atomic instructions, which take no offset
+func (e *Encoder) address(operand api.IExpression) (base uint32, err api.IRuntimeError) {
+	offset, base, err := e.memory(operand)
+	if err != nil {
+		return 0, err
+	}
+
+	if offset != 0 {
+		return 0, e.error("'%s' takes an address without offset, '(register)', got offset %d", e.mnemonic.Lexeme(), offset)
+	}
+
+	return base, nil
+}
+
+// describe names what an operand turned out to be, for example
+// "constant GPIO_BASE" or "integer 5".
+func (e *Encoder) describe(operand api.IExpression, obj interface{}) string {
+	if symbol, isSymbol := obj.(api.ISymbol); isSymbol {
+		return "symbol " + symbol.Name().Lexeme()
+	}
+
+	if operand.Type() == api.VAR_EXPR {
+		name := operand.Name().Lexeme()
+		if scope := e.scopeOf(name); scope != nil && scope.IsConstant(name) {
+			return "constant " + name
+		}
+		return "variable " + name
+	}
+
+	switch obj.(type) {
+	case api.IIntegerLiteral, api.IHexNumberLiteral, api.IBinaryNumberLiteral:
+		return fmt.Sprintf("integer %v", obj)
+	case api.INumberLiteral:
+		return fmt.Sprintf("number %v", obj)
+	case api.IStringLiteral:
+		return fmt.Sprintf("string \"%v\"", obj)
+	case api.ICharLiteral:
+		return fmt.Sprintf("char '%v'", obj)
+	case api.IRegisterLiteral:
+		if obj.(api.IRegisterLiteral).IsFloat() {
+			return fmt.Sprintf("float register %v", obj)
+		}
+		return fmt.Sprintf("register %v", obj)
+	}
+
+	return fmt.Sprintf("'%v'", obj)
+}
+
+// The scope that defines a name as the item sees it, nil if none
+func (e *Encoder) scopeOf(name string) api.IEnvironment {
+	for env := e.item.Environment(); env != nil; env = env.Enclosing() {
+		if _, ok := env.Values()[name]; ok {
+			return env
+		}
+	}
+
+	return nil
+}
+
+func (e *Encoder) registers2(first, second api.IExpression) (r1, r2 uint32, err api.IRuntimeError) {
+	r1, err = e.register(first)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r2, err = e.register(second)
+
+	return r1, r2, err
+}
+
+func (e *Encoder) registers3(first, second, third api.IExpression) (r1, r2, r3 uint32, err api.IRuntimeError) {
+	r1, r2, err = e.registers2(first, second)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	r3, err = e.register(third)
+
+	return r1, r2, r3, err
+}
+
+func (e *Encoder) evaluate(operand api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	return e.item.Interpreter().Evaluate(e.section, e.item, operand)
+}
+
+func (e *Encoder) integer(operand api.IExpression) (value int, err api.IRuntimeError) {
+	if operand.Type() == api.MEMORY_EXPR {
+		return 0, e.error("expected integer, got memory operand")
+	}
+
+	obj, err := e.evaluate(operand)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.integerOf(operand, obj)
+}
+
+// A symbol stands for its address.
+func (e *Encoder) integerOf(operand api.IExpression, obj interface{}) (value int, err api.IRuntimeError) {
+	if symbol, isSymbol := obj.(api.ISymbol); isSymbol {
+		return symbol.Address(), nil
+	}
+
+	value, ok := literals.IntegerOf(obj)
+	if !ok {
+		return 0, e.error("expected integer, got %s", e.describe(operand, obj))
+	}
+
+	return value, nil
+}
+
+// Branch and jump targets are addresses, made relative to the
+// instruction.
+func (e *Encoder) relative(operand api.IExpression) (offset int, err api.IRuntimeError) {
+	target, err := e.integer(operand)
+	if err != nil {
+		return 0, err
+	}
+
+	return target - e.pc, nil
+}
+
+func (e *Encoder) error(format string, args ...interface{}) api.IRuntimeError {
+	return errors.NewRuntimeError(e.mnemonic, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,217 @@
+package encoder_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+// An encoding maps the source of a code section body to the machine
+// code it assembles into for an ISA, an instruction per entry written
+// as in the listing: 8 hex digits for a 32-bit instruction, 4 for a
+// compressed one.
+type encoding struct {
+	isa    string
+	source string
+	want   []string
+}
+
+// Assembles each encoding in a code section with the given attributes
+func checkEncodings(t *testing.T, attributes string, encodings []encoding) {
+	t.Helper()
+
+	for _, enc := range encodings {
+		t.Run(enc.isa+"/"+enc.source, func(t *testing.T) {
+			image := asmtest.Image(t, fmt.Sprintf(`{"ISA":%q}`, enc.isa), attributes+"code text {\n"+enc.source+"\n}\n")
+			want := asmtest.MachineCode(t, enc.want...)
+
+			if !bytes.Equal(image, want) {
+				t.Errorf("got % x, want % x", image, want)
+			}
+		})
+	}
+}
+
+// The instructions of each extension, assembled for an ISA that has it
+func TestEncodings(t *testing.T) {
+	checkEncodings(t, "", []encoding{
+		// RV32I
+		{"rv32i", "add a0, a1, a2", []string{"00c58533"}},
+		{"rv32i", "sub a0, a1, a2", []string{"40c58533"}},
+		{"rv32i", "sll a0, a1, a2", []string{"00c59533"}},
+		{"rv32i", "slt a0, a1, a2", []string{"00c5a533"}},
+		{"rv32i", "sltu a0, a1, a2", []string{"00c5b533"}},
+		{"rv32i", "xor a0, a1, a2", []string{"00c5c533"}},
+		{"rv32i", "srl a0, a1, a2", []string{"00c5d533"}},
+		{"rv32i", "sra a0, a1, a2", []string{"40c5d533"}},
+		{"rv32i", "or a0, a1, a2", []string{"00c5e533"}},
+		{"rv32i", "and a0, a1, a2", []string{"00c5f533"}},
+
+		{"rv32i", "addi a0, a1, -1", []string{"fff58513"}},
+		{"rv32i", "slti a0, a1, 5", []string{"0055a513"}},
+		{"rv32i", "andi a0, a1, 255", []string{"0ff5f513"}},
+		{"rv32i", "slli a0, a1, 31", []string{"01f59513"}},
+		{"rv32i", "srli a0, a1, 1", []string{"0015d513"}},
+		{"rv32i", "srai a0, a1, 3", []string{"4035d513"}},
+
+		{"rv32i", "lw a0, -4(sp)", []string{"ffc12503"}},
+		{"rv32i", "lb a0, 0(a1)", []string{"00058503"}},
+		{"rv32i", "lhu a0, 2(a1)", []string{"0025d503"}},
+		{"rv32i", "sw a0, 8(sp)", []string{"00a12423"}},
+		{"rv32i", "sb a1, -1(a0)", []string{"feb50fa3"}},
+
+		{"rv32i", "lui a0, 0x12345", []string{"12345537"}},
+		{"rv32i", "auipc a0, 1", []string{"00001517"}},
+		{"rv32i", "beq a0, a1, next\nnext:", []string{"00b50263"}},
+		{"rv32i", "top: jal ra, top", []string{"000000ef"}},
+		{"rv32i", "ecall", []string{"00000073"}},
+		{"rv32i", "ebreak", []string{"00100073"}},
+
+		// RV32M
+		{"rv32im", "mul a0, a1, a2", []string{"02c58533"}},
+		{"rv32im", "mulh a0, a1, a2", []string{"02c59533"}},
+		{"rv32im", "mulhsu a0, a1, a2", []string{"02c5a533"}},
+		{"rv32im", "mulhu a0, a1, a2", []string{"02c5b533"}},
+		{"rv32im", "div a0, a1, a2", []string{"02c5c533"}},
+		{"rv32im", "divu a0, a1, a2", []string{"02c5d533"}},
+		{"rv32im", "rem a0, a1, a2", []string{"02c5e533"}},
+		{"rv32im", "remu a0, a1, a2", []string{"02c5f533"}},
+
+		// RV32A
+		{"rv32ima", "lr.w t0, (a0)", []string{"100522af"}},
+		{"rv32ima", "sc.w t0, a1, (a0)", []string{"18b522af"}},
+		{"rv32ima", "amoswap.w a0, a1, (a2)", []string{"08b6252f"}},
+		{"rv32ima", "amoadd.w a0, a1, (a2)", []string{"00b6252f"}},
+		{"rv32ima", "amoxor.w a0, a1, (a2)", []string{"20b6252f"}},
+		{"rv32ima", "amoand.w a0, a1, (a2)", []string{"60b6252f"}},
+		{"rv32ima", "amoor.w a0, a1, (a2)", []string{"40b6252f"}},
+		{"rv32ima", "amomin.w a0, a1, (a2)", []string{"80b6252f"}},
+		{"rv32ima", "amomax.w a0, a1, (a2)", []string{"a0b6252f"}},
+		{"rv32ima", "amominu.w a0, a1, (a2)", []string{"c0b6252f"}},
+		{"rv32ima", "amomaxu.w a0, a1, 0(a2)", []string{"e0b6252f"}},
+
+		{"rv32ima", "lr.w.aq t0, (a0)", []string{"140522af"}},
+		{"rv32ima", "amoswap.w.aq a0, a1, (a2)", []string{"0cb6252f"}},
+		{"rv32ima", "amoswap.w.rl a0, a1, (a2)", []string{"0ab6252f"}},
+		{"rv32ima", "amoswap.w.aqrl a0, a1, (a2)", []string{"0eb6252f"}},
+
+		// RV32F and RV32D
+		{"rv32imafd", "fadd.s fa0, fa1, fa2", []string{"00c5f553"}},
+		{"rv32imafd", "fadd.s fa0, fa1, fa2, rtz", []string{"00c59553"}},
+		{"rv32imafd", "fadd.d fa0, fa1, fa2", []string{"02c5f553"}},
+		{"rv32imafd", "fsub.s fa0, fa1, fa2", []string{"08c5f553"}},
+		{"rv32imafd", "fmul.s fa0, fa1, fa2", []string{"10c5f553"}},
+		{"rv32imafd", "fdiv.s fa0, fa1, fa2", []string{"18c5f553"}},
+		{"rv32imafd", "fsqrt.s fa0, fa1", []string{"5805f553"}},
+		{"rv32imafd", "fsqrt.d fa0, fa1", []string{"5a05f553"}},
+		{"rv32imafd", "fmin.s fa0, fa1, fa2", []string{"28c58553"}},
+		{"rv32imafd", "fmax.s fa0, fa1, fa2", []string{"28c59553"}},
+
+		{"rv32imafd", "fsgnj.s fa0, fa1, fa2", []string{"20c58553"}},
+		{"rv32imafd", "fmv.s fa0, fa1", []string{"20b58553"}},
+		{"rv32imafd", "fneg.d fa0, fa1", []string{"22b59553"}},
+		{"rv32imafd", "fabs.s fa0, fa1", []string{"20b5a553"}},
+
+		{"rv32imafd", "fmadd.s fa0, fa1, fa2, fa3", []string{"68c5f543"}},
+		{"rv32imafd", "fmsub.s fa0, fa1, fa2, fa3", []string{"68c5f547"}},
+		{"rv32imafd", "fnmsub.s fa0, fa1, fa2, fa3", []string{"68c5f54b"}},
+		{"rv32imafd", "fnmadd.s fa0, fa1, fa2, fa3", []string{"68c5f54f"}},
+		{"rv32imafd", "fmadd.d fa0, fa1, fa2, fa3", []string{"6ac5f543"}},
+
+		{"rv32imafd", "fcvt.w.s a0, fa1", []string{"c005f553"}},
+		{"rv32imafd", "fcvt.wu.s a0, fa1", []string{"c015f553"}},
+		{"rv32imafd", "fcvt.s.w fa0, a1", []string{"d005f553"}},
+		{"rv32imafd", "fcvt.s.d fa0, fa1", []string{"4015f553"}},
+		// Exact, so they round to nearest
+		{"rv32imafd", "fcvt.d.w fa0, a1", []string{"d2058553"}},
+		{"rv32imafd", "fcvt.d.s fa0, fa1", []string{"42058553"}},
+
+		{"rv32imafd", "fmv.x.w a0, fa1", []string{"e0058553"}},
+		{"rv32imafd", "fmv.w.x fa0, a1", []string{"f0058553"}},
+		{"rv32imafd", "fclass.s a0, fa1", []string{"e0059553"}},
+		{"rv32imafd", "feq.s a0, fa1, fa2", []string{"a0c5a553"}},
+		{"rv32imafd", "flt.s a0, fa1, fa2", []string{"a0c59553"}},
+		{"rv32imafd", "fle.s a0, fa1, fa2", []string{"a0c58553"}},
+		{"rv32imafd", "feq.d a0, fa1, fa2", []string{"a2c5a553"}},
+
+		{"rv32imafd", "flw fa0, 8(sp)", []string{"00812507"}},
+		{"rv32imafd", "fld fa0, 8(sp)", []string{"00813507"}},
+		{"rv32imafd", "fsw fa0, 8(sp)", []string{"00a12427"}},
+		{"rv32imafd", "fsd fa0, 8(sp)", []string{"00a13427"}},
+
+		// RVC
+		{"rv32imafdc", "addi sp, sp, -16", []string{"717d"}},
+		{"rv32imafdc", "sw ra, 12(sp)", []string{"c606"}},
+		{"rv32imafdc", "lw ra, 12(sp)", []string{"40b2"}},
+		{"rv32imafdc", "addi a0, sp, 8", []string{"0028"}},
+		{"rv32imafdc", "li a0, 5", []string{"4515"}},
+		{"rv32imafdc", "li a1, -32", []string{"5581"}},
+		{"rv32imafdc", "mv s0, a0", []string{"842a"}},
+		{"rv32imafdc", "add a0, a0, a1", []string{"952e"}},
+		{"rv32imafdc", "add a2, zero, a3", []string{"8636"}},
+		{"rv32imafdc", "sub s0, s0, s1", []string{"8c05"}},
+		{"rv32imafdc", "xor a5, a4, a5", []string{"8fb9"}},
+		{"rv32imafdc", "andi a0, a0, 7", []string{"891d"}},
+		{"rv32imafdc", "slli t0, t0, 3", []string{"028e"}},
+		{"rv32imafdc", "srli a0, a0, 3", []string{"810d"}},
+		{"rv32imafdc", "srai a1, a1, 31", []string{"85fd"}},
+		{"rv32imafdc", "lui a0, 0xfffff", []string{"757d"}},
+		{"rv32imafdc", "lui a1, 12", []string{"65b1"}},
+		{"rv32imafdc", "lw a0, 4(a1)", []string{"41c8"}},
+		{"rv32imafdc", "sw a2, 124(a3)", []string{"def0"}},
+		{"rv32imafdc", "flw fa0, 8(sp)", []string{"6522"}},
+		{"rv32imafdc", "fsd fs1, 248(s0)", []string{"bc64"}},
+		{"rv32imafdc", "fld fa0, 504(sp)", []string{"357e"}},
+		{"rv32imafdc", "nop", []string{"0001"}},
+		{"rv32imafdc", "ebreak", []string{"9002"}},
+		{"rv32imafdc", "jalr zero, 0(t0)", []string{"8282"}},
+		{"rv32imafdc", "jalr a0", []string{"9502"}},
+		{"rv32imafdc", "ret", []string{"8082"}},
+
+		// Out of reach of the 16-bit forms
+		{"rv32imafdc", "addi a0, a0, 100", []string{"06450513"}},
+		{"rv32imafdc", "jalr a0, t0", []string{"00028567"}},
+
+		{"rv32imafdc", "c.addi a0, 4", []string{"0511"}},
+		{"rv32imafdc", "c.lwsp a0, 8(sp)", []string{"4522"}},
+
+		// Zicsr, which doesn't depend on the ISA
+		{"rv32i", "csrrw a0, mstatus, a1", []string{"30059573"}},
+		{"rv32i", "csrrs t0, mtvec, zero", []string{"305022f3"}},
+		{"rv32i", "csrrc a0, mie, a1", []string{"3045b573"}},
+		{"rv32i", "csrrwi a0, 0x7c0, 31", []string{"7c0fd573"}},
+		{"rv32i", "csrr a0, mcycle", []string{"b0002573"}},
+		{"rv32i", "csrr a0, 0xc00", []string{"c0002573"}},
+		{"rv32i", "csrw mtvec, t0", []string{"30529073"}},
+		{"rv32i", "csrs mie, a0", []string{"30452073"}},
+		{"rv32i", "csrc mip, a0", []string{"34453073"}},
+		{"rv32i", "csrwi mscratch, 3", []string{"3401d073"}},
+		{"rv32i", "csrsi mstatus, 8", []string{"30046073"}},
+		{"rv32i", "csrci mstatus, 8", []string{"30047073"}},
+
+		{"rv32i", "csr { mdebug = 0x7c0 }\ncsrr a0, mdebug", []string{"7c002573"}},
+		// A constant takes precedence over the CSR name
+		{"rv32i", "const { mie = 0x7c1 }\ncsrr a0, mie", []string{"7c102573"}},
+	})
+}
+
+func TestFloatData(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+data d {
+    float [1.5, -2.0]
+    double [0.1]
+}
+`)
+
+	want := asmtest.MachineCode(t,
+		"3fc00000", "c0000000",
+		// 0.1 at full precision, the low word first
+		"9999999a", "3fb99999",
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
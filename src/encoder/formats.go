@@ -0,0 +1,259 @@
+package encoder
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+type format int
+
+const (
+	// rd, rs1, rs2
+	R_TYPE format = iota
+	// rd, rs1, imm
+	I_TYPE
+	// rd, rs1, shamt
+	SHIFT_TYPE
+	// rd, offset(rs1). Loads and jalr.
+	LOAD_TYPE
+	// rs2, offset(rs1)
+	S_TYPE
+	// rs1, rs2, label
+	B_TYPE
+	// rd, imm
+	U_TYPE
+	// rd, label
+	J_TYPE
+	// No operands
+	SYSTEM_TYPE
+	// rd, (rs1). "lr.w".
+	LR_TYPE
+	// rd, rs2, (rs1). "sc.w" and the AMOs.
+	AMO_TYPE
+	// Float operations on two or three registers, some of them
+	// integer registers, and an optional rounding mode
+	FP_TYPE
+	// fd, fs1, fs2, fs3, the fused multiply-adds
+	FP4_TYPE
+	// fd, offset(rs1)
+	FLOAD_TYPE
+	// fs2, offset(rs1)
+	FSTORE_TYPE
+	// rd, csr, rs1
+	CSR_TYPE
+	// rd, csr, uimm
+	CSRI_TYPE
+)
+
+// Number of operands each format takes
+var operandCounts = map[format]int{
+	R_TYPE:      3,
+	I_TYPE:      3,
+	SHIFT_TYPE:  3,
+	LOAD_TYPE:   2,
+	S_TYPE:      2,
+	B_TYPE:      3,
+	U_TYPE:      2,
+	J_TYPE:      2,
+	SYSTEM_TYPE: 0,
+	LR_TYPE:     2,
+	AMO_TYPE:    3,
+	FP4_TYPE:    4,
+	FLOAD_TYPE:  2,
+	FSTORE_TYPE: 2,
+	CSR_TYPE:    3,
+	CSRI_TYPE:   3,
+}
+
+type encoding struct {
+	format format
+	opcode uint32
+	funct3 uint32
+	// Upper bits for R-type and shifts
+	funct7 uint32
+	// Immediate of the system instructions
+	funct12 uint32
+	// The ISA extension the instruction belongs to, "" for the base
+	extension string
+	// FP_TYPE register operands in order, "f" for a float register and
+	// "x" for an integer one
+	registers string
+	// The fixed rs2 field of FP_TYPE instructions with two registers
+	rs2 uint32
+	// Takes an optional rounding mode
+	rounding bool
+}
+
+// Number of operands, not counting an optional rounding mode
+func (en encoding) operandCount() int {
+	if en.format == FP_TYPE {
+		return len(en.registers)
+	}
+
+	return operandCounts[en.format]
+}
+
+// RV32I base instructions and those of the extensions
+var encodings = map[api.TokenType]encoding{
+	api.ADD:  {format: R_TYPE, opcode: 0x33, funct3: 0x0},
+	api.SUB:  {format: R_TYPE, opcode: 0x33, funct3: 0x0, funct7: 0x20},
+	api.SLL:  {format: R_TYPE, opcode: 0x33, funct3: 0x1},
+	api.SLT:  {format: R_TYPE, opcode: 0x33, funct3: 0x2},
+	api.SLTU: {format: R_TYPE, opcode: 0x33, funct3: 0x3},
+	api.XOR:  {format: R_TYPE, opcode: 0x33, funct3: 0x4},
+	api.SRL:  {format: R_TYPE, opcode: 0x33, funct3: 0x5},
+	api.SRA:  {format: R_TYPE, opcode: 0x33, funct3: 0x5, funct7: 0x20},
+	api.OR:   {format: R_TYPE, opcode: 0x33, funct3: 0x6},
+	api.AND:  {format: R_TYPE, opcode: 0x33, funct3: 0x7},
+
+	api.ADDI:  {format: I_TYPE, opcode: 0x13, funct3: 0x0},
+	api.SLTI:  {format: I_TYPE, opcode: 0x13, funct3: 0x2},
+	api.SLTIU: {format: I_TYPE, opcode: 0x13, funct3: 0x3},
+	api.XORI:  {format: I_TYPE, opcode: 0x13, funct3: 0x4},
+	api.ORI:   {format: I_TYPE, opcode: 0x13, funct3: 0x6},
+	api.ANDI:  {format: I_TYPE, opcode: 0x13, funct3: 0x7},
+
+	api.SLLI: {format: SHIFT_TYPE, opcode: 0x13, funct3: 0x1},
+	api.SRLI: {format: SHIFT_TYPE, opcode: 0x13, funct3: 0x5},
+	api.SRAI: {format: SHIFT_TYPE, opcode: 0x13, funct3: 0x5, funct7: 0x20},
+
+	api.LB:   {format: LOAD_TYPE, opcode: 0x03, funct3: 0x0},
+	api.LH:   {format: LOAD_TYPE, opcode: 0x03, funct3: 0x1},
+	api.LW:   {format: LOAD_TYPE, opcode: 0x03, funct3: 0x2},
+	api.LBU:  {format: LOAD_TYPE, opcode: 0x03, funct3: 0x4},
+	api.LHU:  {format: LOAD_TYPE, opcode: 0x03, funct3: 0x5},
+	api.JALR: {format: LOAD_TYPE, opcode: 0x67, funct3: 0x0},
+
+	api.SB: {format: S_TYPE, opcode: 0x23, funct3: 0x0},
+	api.SH: {format: S_TYPE, opcode: 0x23, funct3: 0x1},
+	api.SW: {format: S_TYPE, opcode: 0x23, funct3: 0x2},
+
+	api.BEQ:  {format: B_TYPE, opcode: 0x63, funct3: 0x0},
+	api.BNE:  {format: B_TYPE, opcode: 0x63, funct3: 0x1},
+	api.BLT:  {format: B_TYPE, opcode: 0x63, funct3: 0x4},
+	api.BGE:  {format: B_TYPE, opcode: 0x63, funct3: 0x5},
+	api.BLTU: {format: B_TYPE, opcode: 0x63, funct3: 0x6},
+	api.BGEU: {format: B_TYPE, opcode: 0x63, funct3: 0x7},
+
+	api.LUI:   {format: U_TYPE, opcode: 0x37},
+	api.AUIPC: {format: U_TYPE, opcode: 0x17},
+
+	api.JAL: {format: J_TYPE, opcode: 0x6f},
+
+	api.ECALL:  {format: SYSTEM_TYPE, opcode: 0x73, funct12: 0x000},
+	api.EBREAK: {format: SYSTEM_TYPE, opcode: 0x73, funct12: 0x001},
+
+	// RV32M
+	api.MUL:    {format: R_TYPE, opcode: 0x33, funct3: 0x0, funct7: 0x01, extension: "M"},
+	api.MULH:   {format: R_TYPE, opcode: 0x33, funct3: 0x1, funct7: 0x01, extension: "M"},
+	api.MULHSU: {format: R_TYPE, opcode: 0x33, funct3: 0x2, funct7: 0x01, extension: "M"},
+	api.MULHU:  {format: R_TYPE, opcode: 0x33, funct3: 0x3, funct7: 0x01, extension: "M"},
+	api.DIV:    {format: R_TYPE, opcode: 0x33, funct3: 0x4, funct7: 0x01, extension: "M"},
+	api.DIVU:   {format: R_TYPE, opcode: 0x33, funct3: 0x5, funct7: 0x01, extension: "M"},
+	api.REM:    {format: R_TYPE, opcode: 0x33, funct3: 0x6, funct7: 0x01, extension: "M"},
+	api.REMU:   {format: R_TYPE, opcode: 0x33, funct3: 0x7, funct7: 0x01, extension: "M"},
+
+	// RV32A. funct7 holds funct5, the aq and rl bits come from the suffix.
+	api.LR_W:      {format: LR_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x08, extension: "A"},
+	api.SC_W:      {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x0c, extension: "A"},
+	api.AMOSWAP_W: {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x04, extension: "A"},
+	api.AMOADD_W:  {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x00, extension: "A"},
+	api.AMOXOR_W:  {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x10, extension: "A"},
+	api.AMOAND_W:  {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x30, extension: "A"},
+	api.AMOOR_W:   {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x20, extension: "A"},
+	api.AMOMIN_W:  {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x40, extension: "A"},
+	api.AMOMAX_W:  {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x50, extension: "A"},
+	api.AMOMINU_W: {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x60, extension: "A"},
+	api.AMOMAXU_W: {format: AMO_TYPE, opcode: 0x2f, funct3: 0x2, funct7: 0x70, extension: "A"},
+
+	// RV32F and RV32D. Rounding instructions take the rounding mode in
+	// funct3, which holds the default.
+	api.FLW:       {format: FLOAD_TYPE, opcode: 0x07, funct3: 0x2, extension: "F"},
+	api.FSW:       {format: FSTORE_TYPE, opcode: 0x27, funct3: 0x2, extension: "F"},
+	api.FLD:       {format: FLOAD_TYPE, opcode: 0x07, funct3: 0x3, extension: "D"},
+	api.FSD:       {format: FSTORE_TYPE, opcode: 0x27, funct3: 0x3, extension: "D"},
+	api.FADD_S:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x00, registers: "fff", rounding: true, extension: "F"},
+	api.FSUB_S:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x04, registers: "fff", rounding: true, extension: "F"},
+	api.FMUL_S:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x08, registers: "fff", rounding: true, extension: "F"},
+	api.FDIV_S:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x0c, registers: "fff", rounding: true, extension: "F"},
+	api.FSQRT_S:   {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x2c, registers: "ff", rounding: true, extension: "F"},
+	api.FSGNJ_S:   {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x10, registers: "fff", extension: "F"},
+	api.FSGNJN_S:  {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x10, registers: "fff", extension: "F"},
+	api.FSGNJX_S:  {format: FP_TYPE, opcode: 0x53, funct3: 0x2, funct7: 0x10, registers: "fff", extension: "F"},
+	api.FMIN_S:    {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x14, registers: "fff", extension: "F"},
+	api.FMAX_S:    {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x14, registers: "fff", extension: "F"},
+	api.FMADD_S:   {format: FP4_TYPE, opcode: 0x43, funct3: 0x7, funct7: 0x00, rounding: true, extension: "F"},
+	api.FMSUB_S:   {format: FP4_TYPE, opcode: 0x47, funct3: 0x7, funct7: 0x00, rounding: true, extension: "F"},
+	api.FNMSUB_S:  {format: FP4_TYPE, opcode: 0x4b, funct3: 0x7, funct7: 0x00, rounding: true, extension: "F"},
+	api.FNMADD_S:  {format: FP4_TYPE, opcode: 0x4f, funct3: 0x7, funct7: 0x00, rounding: true, extension: "F"},
+	api.FCVT_W_S:  {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x60, registers: "xf", rounding: true, extension: "F"},
+	api.FCVT_WU_S: {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x60, registers: "xf", rs2: 1, rounding: true, extension: "F"},
+	api.FCVT_S_W:  {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x68, registers: "fx", rounding: true, extension: "F"},
+	api.FCVT_S_WU: {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x68, registers: "fx", rs2: 1, rounding: true, extension: "F"},
+	api.FEQ_S:     {format: FP_TYPE, opcode: 0x53, funct3: 0x2, funct7: 0x50, registers: "xff", extension: "F"},
+	api.FLT_S:     {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x50, registers: "xff", extension: "F"},
+	api.FLE_S:     {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x50, registers: "xff", extension: "F"},
+	api.FCLASS_S:  {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x70, registers: "xf", extension: "F"},
+	api.FMV_X_W:   {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x70, registers: "xf", extension: "F"},
+	api.FMV_W_X:   {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x78, registers: "fx", extension: "F"},
+	api.FADD_D:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x01, registers: "fff", rounding: true, extension: "D"},
+	api.FSUB_D:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x05, registers: "fff", rounding: true, extension: "D"},
+	api.FMUL_D:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x09, registers: "fff", rounding: true, extension: "D"},
+	api.FDIV_D:    {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x0d, registers: "fff", rounding: true, extension: "D"},
+	api.FSQRT_D:   {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x2d, registers: "ff", rounding: true, extension: "D"},
+	api.FSGNJ_D:   {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x11, registers: "fff", extension: "D"},
+	api.FSGNJN_D:  {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x11, registers: "fff", extension: "D"},
+	api.FSGNJX_D:  {format: FP_TYPE, opcode: 0x53, funct3: 0x2, funct7: 0x11, registers: "fff", extension: "D"},
+	api.FMIN_D:    {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x15, registers: "fff", extension: "D"},
+	api.FMAX_D:    {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x15, registers: "fff", extension: "D"},
+	api.FMADD_D:   {format: FP4_TYPE, opcode: 0x43, funct3: 0x7, funct7: 0x01, rounding: true, extension: "D"},
+	api.FMSUB_D:   {format: FP4_TYPE, opcode: 0x47, funct3: 0x7, funct7: 0x01, rounding: true, extension: "D"},
+	api.FNMSUB_D:  {format: FP4_TYPE, opcode: 0x4b, funct3: 0x7, funct7: 0x01, rounding: true, extension: "D"},
+	api.FNMADD_D:  {format: FP4_TYPE, opcode: 0x4f, funct3: 0x7, funct7: 0x01, rounding: true, extension: "D"},
+	api.FCVT_W_D:  {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x61, registers: "xf", rounding: true, extension: "D"},
+	api.FCVT_WU_D: {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x61, registers: "xf", rs2: 1, rounding: true, extension: "D"},
+	api.FCVT_D_W:  {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x69, registers: "fx", rounding: true, extension: "D"},
+	api.FCVT_D_WU: {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x69, registers: "fx", rs2: 1, rounding: true, extension: "D"},
+	api.FEQ_D:     {format: FP_TYPE, opcode: 0x53, funct3: 0x2, funct7: 0x51, registers: "xff", extension: "D"},
+	api.FLT_D:     {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x51, registers: "xff", extension: "D"},
+	api.FLE_D:     {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x51, registers: "xff", extension: "D"},
+	api.FCLASS_D:  {format: FP_TYPE, opcode: 0x53, funct3: 0x1, funct7: 0x71, registers: "xf", extension: "D"},
+	api.FCVT_S_D:  {format: FP_TYPE, opcode: 0x53, funct3: 0x7, funct7: 0x20, registers: "ff", rs2: 1, rounding: true, extension: "D"},
+	api.FCVT_D_S:  {format: FP_TYPE, opcode: 0x53, funct3: 0x0, funct7: 0x21, registers: "ff", rounding: true, extension: "D"},
+
+	// Zicsr
+	api.CSRRW:  {format: CSR_TYPE, opcode: 0x73, funct3: 0x1},
+	api.CSRRS:  {format: CSR_TYPE, opcode: 0x73, funct3: 0x2},
+	api.CSRRC:  {format: CSR_TYPE, opcode: 0x73, funct3: 0x3},
+	api.CSRRWI: {format: CSRI_TYPE, opcode: 0x73, funct3: 0x5},
+	api.CSRRSI: {format: CSRI_TYPE, opcode: 0x73, funct3: 0x6},
+	api.CSRRCI: {format: CSRI_TYPE, opcode: 0x73, funct3: 0x7},
+}
+
+// ------------------------------------------------------------
+// Field packing. Immediates have already been range checked.
+// ------------------------------------------------------------
+func packR(en encoding, rd, rs1, rs2 uint32) uint32 {
+	return en.funct7<<25 | rs2<<20 | rs1<<15 | en.funct3<<12 | rd<<7 | en.opcode
+}
+
+func packI(en encoding, rd, rs1 uint32, imm int) uint32 {
+	return uint32(imm&0xfff)<<20 | rs1<<15 | en.funct3<<12 | rd<<7 | en.opcode
+}
+
+func packS(en encoding, rs1, rs2 uint32, imm int) uint32 {
+	u := uint32(imm)
+	return (u>>5&0x7f)<<25 | rs2<<20 | rs1<<15 | en.funct3<<12 | (u&0x1f)<<7 | en.opcode
+}
+
+func packB(en encoding, rs1, rs2 uint32, offset int) uint32 {
+	u := uint32(offset)
+	return (u>>12&0x1)<<31 | (u>>5&0x3f)<<25 | rs2<<20 | rs1<<15 | en.funct3<<12 |
+		(u>>1&0xf)<<8 | (u>>11&0x1)<<7 | en.opcode
+}
+
+func packU(en encoding, rd uint32, imm int) uint32 {
+	return uint32(imm&0xfffff)<<12 | rd<<7 | en.opcode
+}
+
+func packJ(en encoding, rd uint32, offset int) uint32 {
+	u := uint32(offset)
+	return (u>>20&0x1)<<31 | (u>>1&0x3ff)<<21 | (u>>11&0x1)<<20 | (u>>12&0xff)<<12 | rd<<7 | en.opcode
+}
@@ -0,0 +1,218 @@
+package encoder
+
+import (
+	"fmt"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner"
+)
+
+// Canonical ABI names, used when listing expansions
+var abiNames = [32]string{
+	"zero", "ra", "sp", "gp", "tp", "t0", "t1", "t2",
+	"s0", "s1", "a0", "a1", "a2", "a3", "a4", "a5",
+	"a6", "a7", "s2", "s3", "s4", "s5", "s6", "s7",
+	"s8", "s9", "s10", "s11", "t3", "t4", "t5", "t6",
+}
+
+// Float register ABI names
+var floatNames = [32]string{
+	"ft0", "ft1", "ft2", "ft3", "ft4", "ft5", "ft6", "ft7",
+	"fs0", "fs1", "fa0", "fa1", "fa2", "fa3", "fa4", "fa5",
+	"fa6", "fa7", "fs2", "fs3", "fs4", "fs5", "fs6", "fs7",
+	"fs8", "fs9", "fs10", "fs11", "ft8", "ft9", "ft10", "ft11",
+}
+
+// Atomic suffixes by their aq and rl bits
+var orderings = [4]string{"", ".rl", ".aq", ".aqrl"}
+
+// A base instruction with its operands evaluated. Branch and jump
+// immediates are already relative to the instruction.
+type instruction struct {
+	mnemonic api.TokenType
+	rd       uint32
+	rs1      uint32
+	rs2      uint32
+	imm      int
+	// The aq and rl bits of an atomic instruction
+	aqrl uint32
+	// The third source of a fused multiply-add
+	rs3 uint32
+	// The rounding mode of a float operation that takes one
+	rm uint32
+	// The CSR a Zicsr instruction accesses
+	csr int
+	// The compressed form it is encoded as, UNDEFINED for its 32-bit
+	// encoding
+	form api.TokenType
+}
+
+// Number of bytes the instruction is encoded in
+func (in instruction) size() int {
+	if in.form != api.UNDEFINED {
+		return 2
+	}
+	return 4
+}
+
+// Checks the immediate against its field and packs the fields into
+// a machine word, or a half word for a compressed instruction.
+func (e *Encoder) pack(in instruction) (word uint32, err api.IRuntimeError) {
+	if in.form != api.UNDEFINED {
+		half, ok := packCompressed(in, in.form)
+		if !ok {
+			in.form = api.UNDEFINED
+			return 0, e.error("'%s' doesn't fit '%s'", in, e.mnemonic.Lexeme())
+		}
+		return half, nil
+	}
+
+	en := encodings[in.mnemonic]
+
+	if en.rounding {
+		en.funct3 = in.rm
+	}
+
+	switch en.format {
+	case R_TYPE:
+		return packR(en, in.rd, in.rs1, in.rs2), nil
+	case LR_TYPE, AMO_TYPE:
+		en.funct7 |= in.aqrl
+		return packR(en, in.rd, in.rs1, in.rs2), nil
+	case FP_TYPE:
+		return packR(en, in.rd, in.rs1, in.rs2), nil
+	case FP4_TYPE:
+		return in.rs3<<27 | packR(en, in.rd, in.rs1, in.rs2), nil
+	case FLOAD_TYPE:
+		err = e.checkSigned(in.imm, 12, "offset")
+		return packI(en, in.rd, in.rs1, in.imm), err
+	case FSTORE_TYPE:
+		err = e.checkSigned(in.imm, 12, "offset")
+		return packS(en, in.rs1, in.rs2, in.imm), err
+	case CSR_TYPE:
+		err = e.checkCSR(in.csr)
+		return packI(en, in.rd, in.rs1, in.csr), err
+	case CSRI_TYPE:
+		if in.imm < 0 || in.imm >= 1<<5 {
+			return 0, e.error("immediate %d does not fit in 5-bit unsigned field", in.imm)
+		}
+		err = e.checkCSR(in.csr)
+		return packI(en, in.rd, uint32(in.imm), in.csr), err
+	case I_TYPE:
+		err = e.checkSigned(in.imm, 12, "immediate")
+		return packI(en, in.rd, in.rs1, in.imm), err
+	case SHIFT_TYPE:
+		if in.imm < 0 || in.imm >= 1<<5 {
+			return 0, e.error("shift amount %d does not fit in 5-bit unsigned field", in.imm)
+		}
+		return packI(en, in.rd, in.rs1, in.imm|int(en.funct7)<<5), nil
+	case LOAD_TYPE:
+		err = e.checkSigned(in.imm, 12, "offset")
+		return packI(en, in.rd, in.rs1, in.imm), err
+	case S_TYPE:
+		err = e.checkSigned(in.imm, 12, "offset")
+		return packS(en, in.rs1, in.rs2, in.imm), err
+	case B_TYPE:
+		err = e.checkRelative(in.imm, 13, "branch offset")
+		return packB(en, in.rs1, in.rs2, in.imm), err
+	case U_TYPE:
+		if in.imm < -(1<<19) || in.imm >= 1<<20 {
+			return 0, e.error("immediate %d does not fit in 20-bit field", in.imm)
+		}
+		return packU(en, in.rd, in.imm), nil
+	case J_TYPE:
+		err = e.checkRelative(in.imm, 21, "jump offset")
+		return packJ(en, in.rd, in.imm), err
+	}
+
+	// SYSTEM_TYPE
+	return en.funct12<<20 | en.opcode, nil
+}
+
+func (e *Encoder) checkSigned(value int, bits uint, what string) api.IRuntimeError {
+	if value < -(1<<(bits-1)) || value >= 1<<(bits-1) {
+		return e.error("%s %d does not fit in %d-bit signed field", what, value, bits)
+	}
+	return nil
+}
+
+func (e *Encoder) checkCSR(csr int) api.IRuntimeError {
+	if csr < 0 || csr >= 1<<12 {
+		return e.error("CSR %#x does not fit in 12-bit field", csr)
+	}
+	return nil
+}
+
+func (e *Encoder) checkRelative(offset int, bits uint, what string) api.IRuntimeError {
+	if offset&1 != 0 {
+		return e.error("%s %d is not a multiple of 2", what, offset)
+	}
+	return e.checkSigned(offset, bits, what)
+}
+
+func (in instruction) String() string {
+	if in.form != api.UNDEFINED {
+		return in.compressedString()
+	}
+
+	name := in.mnemonic.String()
+	rd, rs1, rs2 := abiNames[in.rd], abiNames[in.rs1], abiNames[in.rs2]
+
+	switch encodings[in.mnemonic].format {
+	case R_TYPE:
+		return fmt.Sprintf("%s %s, %s, %s", name, rd, rs1, rs2)
+	case I_TYPE, SHIFT_TYPE:
+		return fmt.Sprintf("%s %s, %s, %d", name, rd, rs1, in.imm)
+	case LOAD_TYPE:
+		return fmt.Sprintf("%s %s, %d(%s)", name, rd, in.imm, rs1)
+	case S_TYPE:
+		return fmt.Sprintf("%s %s, %d(%s)", name, rs2, in.imm, rs1)
+	case B_TYPE:
+		return fmt.Sprintf("%s %s, %s, .%+d", name, rs1, rs2, in.imm)
+	case U_TYPE:
+		return fmt.Sprintf("%s %s, %#x", name, rd, in.imm&0xfffff)
+	case J_TYPE:
+		return fmt.Sprintf("%s %s, .%+d", name, rd, in.imm)
+	case LR_TYPE:
+		return fmt.Sprintf("%s%s %s, (%s)", name, orderings[in.aqrl], rd, rs1)
+	case AMO_TYPE:
+		return fmt.Sprintf("%s%s %s, %s, (%s)", name, orderings[in.aqrl], rd, rs2, rs1)
+	case FP_TYPE:
+		registers := encodings[in.mnemonic].registers
+		fields := []uint32{in.rd, in.rs1, in.rs2}
+		for r, class := range registers {
+			if r > 0 {
+				name += ","
+			}
+			if class == 'f' {
+				name += " " + floatNames[fields[r]]
+			} else {
+				name += " " + abiNames[fields[r]]
+			}
+		}
+		return name
+	case FP4_TYPE:
+		return fmt.Sprintf("%s %s, %s, %s, %s", name, floatNames[in.rd], floatNames[in.rs1], floatNames[in.rs2], floatNames[in.rs3])
+	case FLOAD_TYPE:
+		return fmt.Sprintf("%s %s, %d(%s)", name, floatNames[in.rd], in.imm, rs1)
+	case FSTORE_TYPE:
+		return fmt.Sprintf("%s %s, %d(%s)", name, floatNames[in.rs2], in.imm, rs1)
+	case CSR_TYPE:
+		return fmt.Sprintf("%s %s, %s, %s", name, rd, csrName(in.csr), rs1)
+	case CSRI_TYPE:
+		return fmt.Sprintf("%s %s, %s, %d", name, rd, csrName(in.csr), in.imm)
+	}
+
+	return name
+}
+
+// A CSR by its standard name if it has one
+func csrName(csr int) string {
+	for name, number := range scanner.CSRs {
+		if number == csr {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%#x", csr)
+}
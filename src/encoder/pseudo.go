@@ -0,0 +1,321 @@
+package encoder
+
+import (
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+const (
+	zero = 0
+	ra   = 1
+	sp   = 2
+	gp   = 3
+	t1   = 6
+)
+
+// An expander builds the base instructions a pseudo instruction
+// stands for.
+type expander struct {
+	operands int
+	expand   func(e *Encoder, operands []api.IExpression) (instructions []instruction, err api.IRuntimeError)
+}
+
+var pseudos = map[api.TokenType]expander{
+	api.NOP: {0, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		return []instruction{{mnemonic: api.ADDI}}, nil
+	}},
+	api.LI: {2, (*Encoder).li},
+	api.LA: {2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		if e.item.Relaxed() {
+			return e.gpRelative(rd, operands[1])
+		}
+		hi, lo, err := e.pcrel(operands[1])
+		return []instruction{
+			{mnemonic: api.AUIPC, rd: rd, imm: hi},
+			{mnemonic: api.ADDI, rd: rd, rs1: rd, imm: lo},
+		}, err
+	}},
+
+	api.MV:   unaryImmediate(api.ADDI, 0),
+	api.NOT:  unaryImmediate(api.XORI, -1),
+	api.SEQZ: unaryImmediate(api.SLTIU, 1),
+	api.NEG:  unaryRegister(api.SUB, true),
+	api.SNEZ: unaryRegister(api.SLTU, true),
+	api.SLTZ: unaryRegister(api.SLT, false),
+	api.SGTZ: unaryRegister(api.SLT, true),
+
+	api.BEQZ: branchZero(api.BEQ, false),
+	api.BNEZ: branchZero(api.BNE, false),
+	api.BLEZ: branchZero(api.BGE, true),
+	api.BGEZ: branchZero(api.BGE, false),
+	api.BLTZ: branchZero(api.BLT, false),
+	api.BGTZ: branchZero(api.BLT, true),
+
+	api.BGT:  branchSwapped(api.BLT),
+	api.BLE:  branchSwapped(api.BGE),
+	api.BGTU: branchSwapped(api.BLTU),
+	api.BLEU: branchSwapped(api.BGEU),
+
+	api.J: {1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		offset, err := e.relative(operands[0])
+		return []instruction{{mnemonic: api.JAL, rd: zero, imm: offset}}, err
+	}},
+	api.RET: {0, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		return []instruction{{mnemonic: api.JALR, rd: zero, rs1: ra}}, nil
+	}},
+	api.CALL: farJump(ra, ra),
+	api.TAIL: farJump(zero, t1),
+
+	api.FMV_S:  floatSignInjection(api.FSGNJ_S),
+	api.FNEG_S: floatSignInjection(api.FSGNJN_S),
+	api.FABS_S: floatSignInjection(api.FSGNJX_S),
+	api.FMV_D:  floatSignInjection(api.FSGNJ_D),
+	api.FNEG_D: floatSignInjection(api.FSGNJN_D),
+	api.FABS_D: floatSignInjection(api.FSGNJX_D),
+
+	api.CSRR: {2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		csr, err := e.csr(operands[1])
+		return []instruction{{mnemonic: api.CSRRS, rd: rd, csr: csr, rs1: zero}}, err
+	}},
+	api.CSRW:  csrWrite(api.CSRRW),
+	api.CSRS:  csrWrite(api.CSRRS),
+	api.CSRC:  csrWrite(api.CSRRC),
+	api.CSRWI: csrWrite(api.CSRRWI),
+	api.CSRSI: csrWrite(api.CSRRSI),
+	api.CSRCI: csrWrite(api.CSRRCI),
+}
+
+// Pseudo forms of base mnemonics, told apart by their operands
+var (
+	// jal offset
+	jalPseudo = expander{1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		offset, err := e.relative(operands[0])
+		return []instruction{{mnemonic: api.JAL, rd: ra, imm: offset}}, err
+	}}
+	// jalr rs
+	jalrPseudo = expander{1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rs, err := e.register(operands[0])
+		return []instruction{{mnemonic: api.JALR, rd: ra, rs1: rs}}, err
+	}}
+	// jalr rd, rs
+	jalrLinkPseudo = expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		rs, err := e.register(operands[1])
+		return []instruction{{mnemonic: api.JALR, rd: rd, rs1: rs}}, err
+	}}
+)
+
+// Returns the expander for a pseudo instruction or a pseudo form of
+// a base instruction, otherwise nil.
+func (e *Encoder) pseudo(mnemonic api.TokenType, operands []api.IExpression) *expander {
+	if expander, ok := pseudos[mnemonic]; ok {
+		return &expander
+	}
+
+	switch mnemonic {
+	case api.JAL:
+		if len(operands) == 1 {
+			return &jalPseudo
+		}
+	case api.JALR:
+		if len(operands) == 1 {
+			return &jalrPseudo
+		}
+		if len(operands) == 2 && operands[1].Type() != api.MEMORY_EXPR {
+			return &jalrLinkPseudo
+		}
+	case api.LB, api.LH, api.LW, api.LBU, api.LHU:
+		// l{b|h|w} rd, symbol
+		if len(operands) == 2 && operands[1].Type() != api.MEMORY_EXPR {
+			load := globalLoad(mnemonic)
+			return &load
+		}
+	}
+
+	return nil
+}
+
+// li picks the shortest sequence for a constant. An address always
+// gets lui+addi so the size doesn't depend on where it ends up.
+func (e *Encoder) li(operands []api.IExpression) (instructions []instruction, err api.IRuntimeError) {
+	rd, err := e.register(operands[0])
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := e.evaluate(operands[1])
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := e.integerOf(operands[1], obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(value) < -(1<<31) || int64(value) >= 1<<32 {
+		return nil, e.error("immediate %d does not fit in 32 bits", value)
+	}
+
+	_, isSymbol := obj.(api.ISymbol)
+
+	if isSymbol && e.item.Relaxed() {
+		return e.gpRelative(rd, operands[1])
+	}
+
+	// Wrap to a signed 32-bit value
+	value = int(int32(uint32(value)))
+	hi, lo := split(value)
+
+	switch {
+	case !isSymbol && value == lo:
+		return []instruction{{mnemonic: api.ADDI, rd: rd, rs1: zero, imm: lo}}, nil
+	case !isSymbol && lo == 0:
+		return []instruction{{mnemonic: api.LUI, rd: rd, imm: hi}}, nil
+	}
+
+	return []instruction{
+		{mnemonic: api.LUI, rd: rd, imm: hi},
+		{mnemonic: api.ADDI, rd: rd, rs1: rd, imm: lo},
+	}, nil
+}
+
+// Splits a value into the upper 20 bits, rounded so that adding the
+// sign extended lower 12 bits gives back the value.
+func split(value int) (hi, lo int) {
+	hi = ((value + 0x800) >> 12) & 0xfffff
+	lo = ((value & 0xfff) ^ 0x800) - 0x800
+	return hi, lo
+}
+
+// Splits the distance from the instruction to a target
+func (e *Encoder) pcrel(operand api.IExpression) (hi, lo int, err api.IRuntimeError) {
+	offset, err := e.relative(operand)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hi, lo = split(offset)
+
+	return hi, lo, nil
+}
+
+// ------------------------------------------------------------
+// Expander builders
+// ------------------------------------------------------------
+
+// rd, rs => mnemonic rd, rs, imm
+func unaryImmediate(mnemonic api.TokenType, imm int) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, rs, err := e.registers2(operands[0], operands[1])
+		return []instruction{{mnemonic: mnemonic, rd: rd, rs1: rs, imm: imm}}, err
+	}}
+}
+
+// rd, rs => mnemonic rd, rs, zero or, swapped, mnemonic rd, zero, rs
+func unaryRegister(mnemonic api.TokenType, swap bool) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, rs, err := e.registers2(operands[0], operands[1])
+		if swap {
+			return []instruction{{mnemonic: mnemonic, rd: rd, rs1: zero, rs2: rs}}, err
+		}
+		return []instruction{{mnemonic: mnemonic, rd: rd, rs1: rs, rs2: zero}}, err
+	}}
+}
+
+// rs, offset => mnemonic rs, zero, offset or, swapped, mnemonic zero, rs, offset
+func branchZero(mnemonic api.TokenType, swap bool) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rs, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		offset, err := e.relative(operands[1])
+		if swap {
+			return []instruction{{mnemonic: mnemonic, rs1: zero, rs2: rs, imm: offset}}, err
+		}
+		return []instruction{{mnemonic: mnemonic, rs1: rs, rs2: zero, imm: offset}}, err
+	}}
+}
+
+// rs, rt, offset => mnemonic rt, rs, offset
+func branchSwapped(mnemonic api.TokenType) expander {
+	return expander{3, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rs, rt, err := e.registers2(operands[0], operands[1])
+		if err != nil {
+			return nil, err
+		}
+		offset, err := e.relative(operands[2])
+		return []instruction{{mnemonic: mnemonic, rs1: rt, rs2: rs, imm: offset}}, err
+	}}
+}
+
+// call and tail: auipc through a scratch register then jalr, linking
+// into rd. Relaxed they are a single jal.
+func farJump(rd, scratch uint32) expander {
+	return expander{1, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		if e.item.Relaxed() {
+			offset, err := e.relative(operands[0])
+			return []instruction{{mnemonic: api.JAL, rd: rd, imm: offset}}, err
+		}
+		hi, lo, err := e.pcrel(operands[0])
+		return []instruction{
+			{mnemonic: api.AUIPC, rd: scratch, imm: hi},
+			{mnemonic: api.JALR, rd: rd, rs1: scratch, imm: lo},
+		}, err
+	}}
+}
+
+// rd, symbol => auipc rd, hi ; load rd, lo(rd)
+func globalLoad(mnemonic api.TokenType) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.register(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, lo, err := e.pcrel(operands[1])
+		return []instruction{
+			{mnemonic: api.AUIPC, rd: rd, imm: hi},
+			{mnemonic: mnemonic, rd: rd, rs1: rd, imm: lo},
+		}, err
+	}}
+}
+
+// op fd, fs is the sign injection of fs with itself
+func floatSignInjection(mnemonic api.TokenType) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		rd, err := e.floatRegister(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		rs, err := e.floatRegister(operands[1])
+		return []instruction{{mnemonic: mnemonic, rd: rd, rs1: rs, rs2: rs}}, err
+	}}
+}
+
+// csr, rs => mnemonic zero, csr, rs and likewise for an immediate
+func csrWrite(mnemonic api.TokenType) expander {
+	return expander{2, func(e *Encoder, operands []api.IExpression) ([]instruction, api.IRuntimeError) {
+		csr, err := e.csr(operands[0])
+		if err != nil {
+			return nil, err
+		}
+		in := instruction{mnemonic: mnemonic, rd: zero, csr: csr}
+		if encodings[mnemonic].format == CSRI_TYPE {
+			in.imm, err = e.integer(operands[1])
+		} else {
+			in.rs1, err = e.register(operands[1])
+		}
+		return []instruction{in}, err
+	}}
+}
@@ -0,0 +1,60 @@
+package encoder_test
+
+import "testing"
+
+func TestLi(t *testing.T) {
+	checkEncodings(t, "", []encoding{
+		{"rv32i", "li a0, 0", []string{"00000513"}},
+		{"rv32i", "li a0, -1", []string{"fff00513"}},
+		{"rv32i", "li a0, 2047", []string{"7ff00513"}},
+		{"rv32i", "li a0, -2048", []string{"80000513"}},
+		// The low part is sign extended so lui rounds up
+		{"rv32i", "li a0, 2048", []string{"00001537", "80050513"}},
+		{"rv32i", "li a0, -2049", []string{"fffff537", "7ff50513"}},
+		{"rv32i", "li a0, 0x1000", []string{"00001537"}},
+		{"rv32i", "li a0, 0x12345678", []string{"12345537", "67850513"}},
+		{"rv32i", "li a0, 0x12345800", []string{"12346537", "80050513"}},
+		{"rv32i", "li a0, 0x7fffffff", []string{"80000537", "fff50513"}},
+		{"rv32i", "li a0, 0x80000000", []string{"80000537"}},
+		{"rv32i", "li a0, 0xffffffff", []string{"fff00513"}},
+		// An address always gets both
+		{"rv32i", "top: li a0, top", []string{"00000537", "00050513"}},
+	})
+}
+
+func TestPseudoExpansion(t *testing.T) {
+	checkEncodings(t, "", []encoding{
+		{"rv32i", "nop", []string{"00000013"}},
+		{"rv32i", "mv a0, a1", []string{"00058513"}},
+		{"rv32i", "not a0, a1", []string{"fff5c513"}},
+		{"rv32i", "neg a0, a1", []string{"40b00533"}},
+		{"rv32i", "seqz a0, a1", []string{"0015b513"}},
+		{"rv32i", "snez a0, a1", []string{"00b03533"}},
+		{"rv32i", "sltz a0, a1", []string{"0005a533"}},
+		{"rv32i", "sgtz a0, a1", []string{"00b02533"}},
+
+		{"rv32i", "top: beqz a0, top", []string{"00050063"}},
+		{"rv32i", "bnez a0, next\nnext:", []string{"00051263"}},
+		{"rv32i", "top: blez a0, top", []string{"00a05063"}},
+		{"rv32i", "top: bgez a0, top", []string{"00055063"}},
+		{"rv32i", "top: bltz a0, top", []string{"00054063"}},
+		{"rv32i", "top: bgtz a0, top", []string{"00a04063"}},
+		{"rv32i", "top: bgt a0, a1, top", []string{"00a5c063"}},
+		{"rv32i", "top: ble a0, a1, top", []string{"00a5d063"}},
+		{"rv32i", "top: bgtu a0, a1, top", []string{"00a5e063"}},
+		{"rv32i", "top: bleu a0, a1, top", []string{"00a5f063"}},
+
+		{"rv32i", "top: j top", []string{"0000006f"}},
+		{"rv32i", "top: jal top", []string{"000000ef"}},
+		{"rv32i", "jalr a0", []string{"000500e7"}},
+		{"rv32i", "jalr a0, t0", []string{"00028567"}},
+		{"rv32i", "jalr a0, 4(t0)", []string{"00428567"}},
+		{"rv32i", "ret", []string{"00008067"}},
+
+		{"rv32i", "top: call top", []string{"00000097", "000080e7"}},
+		{"rv32i", "top: tail top", []string{"00000317", "00030067"}},
+		{"rv32i", "top: la a0, top", []string{"00000517", "00050513"}},
+		{"rv32i", "top: lw a0, top", []string{"00000517", "00052503"}},
+		{"rv32i", "top: lbu a1, top", []string{"00000597", "0005c583"}},
+	})
+}
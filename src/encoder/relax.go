@@ -0,0 +1,189 @@
+package encoder
+
+import (
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// Relax reports whether an item can take its short form at the current
+// layout:
+//
+//	call/tail      => jal, when the target is within ±1 MiB
+//	la, li symbol  => addi rd, gp, offset, when within ±2 KiB of gp
+//	lui + addi     => addi rd, gp, offset, for a "%hi(x)"/"%lo(x)" pair
+//
+// Anything that can't be worked out isn't relaxed; encoding reports it.
+func (e *Encoder) Relax(section api.ISection, item api.ISectionItem) bool {
+	e.begin(section, item)
+
+	operands := item.Statement().Operands()
+
+	switch e.mnemonic.Type() {
+	case api.CALL, api.TAIL:
+		if len(operands) != 1 {
+			return false
+		}
+		offset, err := e.relative(operands[0])
+		return err == nil && e.checkRelative(offset, 21, "") == nil
+
+	case api.LA, api.LI:
+		if len(operands) != 2 {
+			return false
+		}
+		rd, ok := e.registerAt(item, operands[0])
+		if !ok {
+			return false
+		}
+		obj, err := e.evaluate(operands[1])
+		if err != nil {
+			return false
+		}
+		symbol, isSymbol := obj.(api.ISymbol)
+		return isSymbol && e.nearGp(rd, symbol.Address())
+
+	case api.LUI:
+		rd, target, ok := e.pair(item, e.neighbour(item, 1))
+		return ok && e.nearGp(rd, target)
+
+	case api.ADDI:
+		rd, target, ok := e.pair(e.neighbour(item, -1), item)
+		return ok && e.nearGp(rd, target)
+	}
+
+	return false
+}
+
+// The instruction directly before or after an item. A label in
+// between means it may be jumped to so there is none.
+func (e *Encoder) neighbour(item api.ISectionItem, step int) api.ISectionItem {
+	items := e.section.Items()
+
+	for index, it := range items {
+		if it != item {
+			continue
+		}
+
+		next := index + step
+		if next < 0 || next >= len(items) || items[next].Kind() != api.ITEM_INSTRUCTION {
+			return nil
+		}
+		return items[next]
+	}
+
+	return nil
+}
+
+// Matches "lui rd, %hi(x)" followed by "addi rd, rd, %lo(x)" and
+// returns rd and x.
+func (e *Encoder) pair(lui, addi api.ISectionItem) (rd, target int, ok bool) {
+	if lui == nil || addi == nil {
+		return 0, 0, false
+	}
+
+	luiStatement, addiStatement := lui.Statement(), addi.Statement()
+	if luiStatement.Mnemonic().Type() != api.LUI || addiStatement.Mnemonic().Type() != api.ADDI {
+		return 0, 0, false
+	}
+
+	luiOperands, addiOperands := luiStatement.Operands(), addiStatement.Operands()
+	if len(luiOperands) != 2 || len(addiOperands) != 3 ||
+		!isRelocation(luiOperands[1], api.HI) || !isRelocation(addiOperands[2], api.LO) {
+		return 0, 0, false
+	}
+
+	rd, ok = e.registerAt(lui, luiOperands[0])
+	if !ok {
+		return 0, 0, false
+	}
+
+	for _, operand := range addiOperands[:2] {
+		if r, ok := e.registerAt(addi, operand); !ok || r != rd {
+			return 0, 0, false
+		}
+	}
+
+	hi, ok := e.integerAt(lui, luiOperands[1].Expression())
+	if !ok {
+		return 0, 0, false
+	}
+
+	lo, ok := e.integerAt(addi, addiOperands[2].Expression())
+	if !ok || hi != lo {
+		return 0, 0, false
+	}
+
+	return rd, hi, true
+}
+
+func isRelocation(operand api.IExpression, operator api.TokenType) bool {
+	return operand.Type() == api.RELOCATION_EXPR && operand.Operator().Type() == operator
+}
+
+func (e *Encoder) registerAt(item api.ISectionItem, operand api.IExpression) (number int, ok bool) {
+	obj, err := item.Interpreter().Evaluate(e.section, item, operand)
+	if err != nil {
+		return 0, false
+	}
+
+	register, ok := obj.(api.IRegisterLiteral)
+	if !ok {
+		return 0, false
+	}
+
+	return register.RegisterValue(), true
+}
+
+func (e *Encoder) integerAt(item api.ISectionItem, operand api.IExpression) (value int, ok bool) {
+	obj, err := item.Interpreter().Evaluate(e.section, item, operand)
+	if err != nil {
+		return 0, false
+	}
+
+	value, err = e.integerOf(operand, obj)
+
+	return value, err == nil
+}
+
+// The address held in gp, given by the "GlobalPointer" setting
+func (e *Encoder) globalPointer() (address int, ok bool) {
+	name := e.assembler.Properties().GlobalPointer()
+	if name == "" {
+		return 0, false
+	}
+
+	symbol, ok := e.assembler.Symbols().Lookup(name)
+	if !ok {
+		return 0, false
+	}
+
+	return symbol.Address(), true
+}
+
+// Whether rd can be loaded with target as an offset from gp. gp itself
+// can't, that's the instruction setting it up.
+func (e *Encoder) nearGp(rd, target int) bool {
+	if rd == gp {
+		return false
+	}
+
+	address, ok := e.globalPointer()
+	if !ok {
+		return false
+	}
+
+	return e.checkSigned(target-address, 12, "") == nil
+}
+
+// rd = x, as an offset from gp
+func (e *Encoder) gpRelative(rd uint32, operand api.IExpression) (instructions []instruction, err api.IRuntimeError) {
+	target, err := e.integer(operand)
+	if err != nil {
+		return nil, err
+	}
+
+	address, ok := e.globalPointer()
+	if !ok {
+		return nil, e.error("relaxed against gp but 'GlobalPointer' isn't defined")
+	}
+
+	return []instruction{{mnemonic: api.ADDI, rd: rd, rs1: gp, imm: target - address}}, nil
+}
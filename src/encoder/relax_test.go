@@ -0,0 +1,100 @@
+package encoder_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestRelaxation(t *testing.T) {
+	image := asmtest.Image(t, `{"Relax":true,"GlobalPointer":"gpbase"}`, `
+[at 0x1000] code main {
+    call near
+    tail near
+    call far
+    la a0, v
+    li a1, v
+    lui a2, %hi(v)
+    addi a2, a2, %lo(v)
+    la a0, big
+    lui a3, %hi(v)
+x:  addi a3, a3, %lo(v)
+}
+[noRelax] code other { call near }
+code nearby { near: ret }
+[at 0x200000] code fars { far: ret }
+[at 0x4000] data d {
+    word gpbase
+    word v
+}
+[at 0x5000] data e { word big }
+`)
+
+	want := asmtest.MachineCode(t,
+		"034000ef",             // jal ra, near
+		"0300006f",             // jal zero, near
+		"001ff097", "ff8080e7", // far is out of reach of jal
+		"00418513",             // addi a0, gp, 4
+		"00418593",             // addi a1, gp, 4
+		"00418613",             // the lui is relaxed away
+		"00004517", "fe450513", // big is out of reach of gp
+		"000046b7", "00468693", // a label between them keeps the pair
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
+
+func TestLongBranch(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+[at 0x1000] code main {
+top:
+    beq a0, a1, far
+    bgtz a0, far
+    bne a0, a1, top
+    bltu a0, a1, far
+}
+[at 0x3000] code fars { far: ret }
+`)
+
+	want := asmtest.MachineCode(t,
+		"00b51463", "7fd0106f", // bne a0, a1, .+8; jal zero, far
+		"00a05463", "7f50106f", // bge zero, a0, .+8; jal zero, far
+		"feb518e3",             // top is in reach
+		"00b57463", "7e90106f", // bgeu a0, a1, .+8; jal zero, far
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
+
+// The instructions setting up gp must load it, not add 0 to it
+func TestGpNotRelaxed(t *testing.T) {
+	image := asmtest.Image(t, `{"Relax":true,"GlobalPointer":"gpbase"}`, `
+[at 0x1000] code main {
+    la gp, gpbase
+    li gp, gpbase
+    lui gp, %hi(gpbase)
+    addi gp, gp, %lo(gpbase)
+    la a0, v
+}
+[at 0x4000] data d {
+    word gpbase
+    word v
+}
+`)
+
+	want := asmtest.MachineCode(t,
+		"00003197", "00018193", // auipc gp, 3; addi gp, gp, 0
+		"000041b7", "00018193", // lui gp, 4; addi gp, gp, 0
+		"000041b7", "00018193",
+		"00418513", // addi a0, gp, 4
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
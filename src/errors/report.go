@@ -2,6 +2,7 @@ package errors
 
 import (
 	"log"
+	"path/filepath"
 
 	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
 )
@@ -21,3 +22,15 @@ func (r *Report) ReportLine(line int, message string) {
 func (r *Report) ReportWhere(line int, where, message string) {
 	log.Printf("[line %d] Error %s : %s", line, where, message)
 }
+
+func (r *Report) ReportWarning(line int, message string) {
+	log.Printf("[line %d] Warning: %s", line, message)
+}
+
+func (r *Report) ReportIn(file string, line int, message string) {
+	log.Printf("[%s line %d] Error: %s", filepath.Base(file), line, message)
+}
+
+func (r *Report) ReportWarningIn(file string, line int, message string) {
+	log.Printf("[%s line %d] Warning: %s", filepath.Base(file), line, message)
+}
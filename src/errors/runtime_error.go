@@ -22,6 +22,12 @@ func NewRuntimeError(token api.IToken, message string) api.IRuntimeError {
 	return o
 }
 
+// Names the invocation a macro body was expanded from, to follow a
+// message about the body.
+func InMacro(invocation api.IToken) string {
+	return fmt.Sprintf(" (in macro '%s' invoked on line %d)", invocation.Lexeme(), invocation.Line())
+}
+
 func (r *RuntimeError) Token() api.IToken {
 	return r.token
 }
@@ -0,0 +1,102 @@
+// Package asmtest assembles sources in a temporary directory for the
+// tests of the other packages.
+package asmtest
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// Run writes files next to a config.json holding config as its
+// "Config" object and assembles "main.S". It returns the assembler
+// and what was reported while it ran.
+func Run(t *testing.T, config string, files map[string]string) (assembler api.IAssembler, report string, err error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["config.json"] = `{"Config":` + config + `}`
+	for name, text := range files {
+		err = os.WriteFile(filepath.Join(dir, name), []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buffer bytes.Buffer
+	writer, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buffer)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+	}()
+
+	assembler, _ = src.NewAssembler()
+	assembler.Configure(dir)
+
+	err = assembler.Run("main.S")
+
+	return assembler, buffer.String(), err
+}
+
+// Assemble assembles source as "main.S" and fails the test if it
+// doesn't assemble.
+func Assemble(t *testing.T, config, source string) api.IAssembler {
+	t.Helper()
+
+	assembler, report, err := Run(t, config, map[string]string{"main.S": source})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, report)
+	}
+
+	return assembler
+}
+
+// Failure assembles source as "main.S", fails the test if it
+// assembles and otherwise returns the error followed by what was
+// reported.
+func Failure(t *testing.T, config, source string) string {
+	t.Helper()
+
+	_, report, err := Run(t, config, map[string]string{"main.S": source})
+	if err == nil {
+		t.Fatalf("assembled:\n%s", source)
+	}
+
+	return err.Error() + "\n" + report
+}
+
+// Image returns the image of the first section of source
+func Image(t *testing.T, config, source string) []byte {
+	t.Helper()
+
+	return Assemble(t, config, source).Sections()[0].Image()
+}
+
+// MachineCode lays out codes written as in the listing, 8 hex digits
+// for a 32-bit instruction or 4 for a compressed one, as little endian
+// machine code.
+func MachineCode(t *testing.T, codes ...string) []byte {
+	t.Helper()
+
+	var code []byte
+	for _, hex := range codes {
+		word, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for b := 0; b < len(hex)/2; b++ {
+			code = append(code, byte(word>>(8*b)))
+		}
+	}
+
+	return code
+}
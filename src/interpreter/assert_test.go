@@ -0,0 +1,52 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		report string
+	}{
+		// Checked after layout, so sizes and addresses are known
+		{"size", "code text {\n    nop\n    nop\n}\nassert(sizeof(text) == 4, \"text too big\");",
+			"[main.S line 5] Error: Assertion failed: text too big"},
+		{"address", "code text { nop }\ndata d { word v [1] }\nassert(addressOf(v) == 0, \"v moved\");",
+			"[main.S line 3] Error: Assertion failed: v moved"},
+		{"error", "var n = 3;\nif (n > 2) { error(\"n is \" + \"too large\"); }",
+			"[main.S line 2] Error: n is too large"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if failure := asmtest.Failure(t, `{}`, test.source); !strings.Contains(failure, test.report) {
+				t.Errorf("got %q, want %q", failure, test.report)
+			}
+		})
+	}
+}
+
+func TestPassingAssertionsAndWarnings(t *testing.T) {
+	_, report, err := asmtest.Run(t, `{}`, map[string]string{"main.S": `
+code text { nop }
+data d { word v [1] }
+assert(sizeof(text) == 4, "text too big");
+assert(addressOf(v) == 4, "v moved");
+warning("still experimental");
+`})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, report)
+	}
+
+	if !strings.Contains(report, "[main.S line 6] Warning: still experimental") {
+		t.Errorf("got report %q", report)
+	}
+	if strings.Contains(report, "Error") {
+		t.Errorf("got report %q", report)
+	}
+}
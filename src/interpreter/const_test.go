@@ -0,0 +1,48 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestConstants(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+const {
+    BASE = 0x100,
+    NEXT = BASE + 4,
+}
+code text {
+    li a0, NEXT
+    addi a1, a1, BASE
+}
+`)
+
+	want := asmtest.MachineCode(t, "10400513", "10058593")
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
+
+func TestConstantsAreImmutable(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"assigned", "const { A = 1 }\nA = 2;", "Can't assign to constant 'A'."},
+		{"assigned in a function", "const { A = 1 }\nfun f() { A = 3; }\nf();", "Can't assign to constant 'A'."},
+		{"redefined", "const { A = 1 }\nconst { A = 2 }", "Constant 'A' already defined."},
+		{"redeclared as a variable", "const { A = 1 }\nvar A = 2;", "'A' already defined."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if failure := asmtest.Failure(t, `{}`, test.source); !strings.Contains(failure, test.err) {
+				t.Errorf("got %q, want %q", failure, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestDataElements(t *testing.T) {
+	assembler := asmtest.Assemble(t, `{}`, `
+data d {
+    string greeting "hi"
+    char c ['A']
+    half h [0x1234, -1]
+    byte b [1, 255, -128]
+    word w [0xdeadbeef]
+    word [4]
+    int<8> l [-2]
+    byte [7]
+    int<2> [-1]
+}
+`)
+
+	// Each element at its natural alignment, padding is zero
+	want := []byte{
+		'h', 'i', 0, 'A',
+		0x34, 0x12, 0xff, 0xff,
+		0x01, 0xff, 0x80, 0x00,
+		0xef, 0xbe, 0xad, 0xde,
+		0x04, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+		0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x07, 0x00, 0xff, 0xff,
+	}
+
+	section := assembler.Sections()[0]
+	if image := section.Image(); !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+
+	offsets := map[string]int{"greeting": 0, "c": 3, "h": 4, "b": 8, "w": 12, "l": 24}
+	for name, offset := range offsets {
+		symbol, ok := assembler.Symbols().Lookup(name)
+		if !ok || symbol.Address() != offset {
+			t.Errorf("got '%s' at %#x, want %#x", name, symbol.Address(), offset)
+		}
+	}
+}
+
+func TestDataRanges(t *testing.T) {
+	tests := []struct {
+		element string
+		report  string
+	}{
+		{"byte [256]", "Value 0x100 does not fit in 'byte'."},
+		{"byte [-129]", "Value -0x81 does not fit in 'byte'."},
+		{"half [0x10000]", "Value 0x10000 does not fit in 'half'."},
+		{"half [-32769]", "Value -0x8001 does not fit in 'half'."},
+		{"int<2> [0x10000]", "Value 0x10000 does not fit in 'int'."},
+		{"int<3> [1]", "Integer size must be 1, 2, 4 or 8 bytes."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.element, func(t *testing.T) {
+			_, report, err := asmtest.Run(t, `{}`, map[string]string{
+				"main.S": "data d {\n    " + test.element + "\n}\n",
+			})
+			if err == nil {
+				t.Fatal("assembled")
+			}
+
+			if !strings.Contains(report, test.report) {
+				t.Errorf("got report %q, want %q", report, test.report)
+			}
+		})
+	}
+
+	// The extremes of either signedness fit
+	asmtest.Assemble(t, `{}`, "data d { byte [255, -128] half [0xffff, -32768] word [0xffffffff, -0x80000000] }")
+}
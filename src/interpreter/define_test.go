@@ -0,0 +1,66 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestConditionalAssembly(t *testing.T) {
+	source := `
+code text {
+    if (defined("BOARD") and BOARD == "hifive1") {
+        li a0, CLOCK
+    }
+    if (defined("DEBUG") and DEBUG) { ebreak } else { nop }
+}
+data d {
+    if (defined("CLOCK")) { byte [1] } else { half [2] }
+}
+`
+
+	tests := []struct {
+		name    string
+		defines string
+		text    []string
+		data    []byte
+	}{
+		{"defined", `{"BOARD":"hifive1","DEBUG":true,"CLOCK":16}`,
+			[]string{"01000513", "00100073"}, []byte{1}},
+		{"other board", `{"BOARD":"arty","DEBUG":false,"CLOCK":16}`,
+			[]string{"00000013"}, []byte{1}},
+		{"none", `{}`,
+			[]string{"00000013"}, []byte{2, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sections := asmtest.Assemble(t, `{"Defines":`+test.defines+`}`, source).Sections()
+
+			if image, want := sections[0].Image(), asmtest.MachineCode(t, test.text...); !bytes.Equal(image, want) {
+				t.Errorf("got text % x, want % x", image, want)
+			}
+			if image := sections[1].Image(); !bytes.Equal(image, test.data) {
+				t.Errorf("got data % x, want % x", image, test.data)
+			}
+		})
+	}
+}
+
+// A top level if can hold whole sections
+func TestConditionalSections(t *testing.T) {
+	source := `
+if (defined("DEBUG")) {
+    code debug { ebreak }
+}
+code text { nop }
+`
+
+	if count := len(asmtest.Assemble(t, `{"Defines":{"DEBUG":true}}`, source).Sections()); count != 2 {
+		t.Errorf("got %d sections with DEBUG, want 2", count)
+	}
+	if count := len(asmtest.Assemble(t, `{}`, source).Sections()); count != 1 {
+		t.Errorf("got %d sections without DEBUG, want 1", count)
+	}
+}
@@ -0,0 +1,59 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestEmitFromFunctionsAndLoops(t *testing.T) {
+	assembler, report, err := asmtest.Run(t, `{}`, map[string]string{
+		"main.S": `
+import { "lib" as lib }
+
+fun clear(n) {
+    for (var i = 0; i < n; i = i + 1) {
+        sw zero, i*4(a0)
+    }
+}
+
+code text {
+    clear(3);
+    var r = a1;
+    addi r, r, 1
+    lib.pad();
+    ret
+}
+`,
+		"lib.S": "fun pad() { nop }",
+	})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, report)
+	}
+
+	want := asmtest.MachineCode(t,
+		// Each store keeps the i it was emitted with
+		"00052023", // sw zero, 0(a0)
+		"00052223", // sw zero, 4(a0)
+		"00052423", // sw zero, 8(a0)
+		"00158593", // addi a1, a1, 1
+		// Emitted by another module's function into this section
+		"00000013",
+		"00008067",
+	)
+
+	if image := assembler.Sections()[0].Image(); !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
+
+func TestEmitOutsideCode(t *testing.T) {
+	for _, source := range []string{"nop", "fun f() { nop }\nf();"} {
+		failure := asmtest.Failure(t, `{}`, source)
+		if !strings.Contains(failure, "Instruction 'nop' outside of a code section.") {
+			t.Errorf("got %q", failure)
+		}
+	}
+}
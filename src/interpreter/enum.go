@@ -0,0 +1,31 @@
+package interpreter
+
+import (
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Enum
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// The members of an "enum" or "flags" declaration, accessed as
+// "Color.RED". They can't be assigned.
+type Enum struct {
+	name    api.IToken
+	members map[string]api.IIntegerLiteral
+}
+
+func NewEnum(name api.IToken) *Enum {
+	o := new(Enum)
+	o.name = name
+	o.members = map[string]api.IIntegerLiteral{}
+	return o
+}
+
+func (e *Enum) Member(name string) (value api.IIntegerLiteral, ok bool) {
+	value, ok = e.members[name]
+	return value, ok
+}
+
+func (e Enum) String() string {
+	return "<enum " + e.name.Lexeme() + ">"
+}
@@ -0,0 +1,54 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestEnumsAndFlags(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+enum Color { RED, GREEN = 5, BLUE }
+flags Irq { TIMER, UART, GPIO = 7 }
+var flags = 1;
+code text {
+    li a0, Color.BLUE
+    li a1, Irq.UART | Irq.GPIO
+    li a2, Color.RED
+    li a3, Irq.TIMER + flags
+}
+`)
+
+	want := asmtest.MachineCode(t,
+		"00600513", // li a0, 6
+		"08200593", // li a1, 0x82
+		"00000613", // li a2, 0
+		"00200693", // li a3, 2
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
+
+func TestEnumErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"duplicate", "enum E { A, A }", "[main.S line 1] Error: Member 'A' is already declared in 'E'."},
+		{"unknown", "enum E { A }\ncode text {\n    li a0, E.Z\n}", "[main.S line 3] Error: 'E' has no member 'Z'."},
+		{"assigned", "enum E { A }\nE.A = 3;", "Invalid assignment target."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if failure := asmtest.Failure(t, `{}`, test.source); !strings.Contains(failure, test.err) {
+				t.Errorf("got %q, want %q", failure, test.err)
+			}
+		})
+	}
+}
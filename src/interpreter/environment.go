@@ -8,12 +8,15 @@ import (
 type Environment struct {
 	enclosing api.IEnvironment
 	values    map[string]interface{}
+	// Names defined by "const" blocks
+	constants map[string]bool
 }
 
 // for the global scope’s environment
 func NewEnvironment() api.IEnvironment {
 	o := new(Environment)
 	o.values = make(map[string]interface{})
+	o.constants = make(map[string]bool)
 	o.enclosing = nil
 	return o
 }
@@ -22,6 +25,7 @@ func NewEnvironment() api.IEnvironment {
 func NewEnvironmentEnclosing(enclosing api.IEnvironment) api.IEnvironment {
 	o := new(Environment)
 	o.values = make(map[string]interface{})
+	o.constants = make(map[string]bool)
 	o.enclosing = enclosing
 	return o
 }
@@ -36,6 +40,21 @@ func (e *Environment) Define(name string, obj interface{}) (err api.IRuntimeErro
 	return errors.NewRuntimeError(nil, "Variable '"+name+"' already defined.")
 }
 
+func (e *Environment) DefineConstant(name string, obj interface{}) (err api.IRuntimeError) {
+	err = e.Define(name, obj)
+	if err != nil {
+		return errors.NewRuntimeError(nil, "Constant '"+name+"' already defined.")
+	}
+
+	e.constants[name] = true
+
+	return nil
+}
+
+func (e *Environment) IsConstant(name string) bool {
+	return e.constants[name]
+}
+
 func (e *Environment) Enclosing() api.IEnvironment {
 	return e.enclosing
 }
@@ -81,19 +100,27 @@ func (e *Environment) ancestor(distance int) api.IEnvironment {
 func (e *Environment) Assign(name api.IToken, value interface{}) (err api.IRuntimeError) {
 	_, ok := e.values[name.Lexeme()]
 	if ok {
+		if e.constants[name.Lexeme()] {
+			return errors.NewRuntimeError(name, "Can't assign to constant '"+name.Lexeme()+"'.")
+		}
 		e.values[name.Lexeme()] = value
 		return nil
 	}
 
 	if e.enclosing != nil {
-		e.enclosing.Assign(name, value)
-		return nil
+		return e.enclosing.Assign(name, value)
 	}
 
 	return errors.NewRuntimeError(name, "Undefined variable '"+name.Lexeme()+"'.")
 }
 
 func (e *Environment) AssignAt(distance int, name api.IToken, value interface{}) (err api.IRuntimeError) {
-	e.ancestor(distance).Values()[name.Lexeme()] = value
+	ancestor := e.ancestor(distance)
+
+	if ancestor.IsConstant(name.Lexeme()) {
+		return errors.NewRuntimeError(name, "Can't assign to constant '"+name.Lexeme()+"'.")
+	}
+
+	ancestor.Values()[name.Lexeme()] = value
 	return nil
 }
@@ -45,6 +45,14 @@ func (e *BaseExpression) Arguments() []api.IExpression {
 	return nil
 }
 
+func (e *BaseExpression) Object() api.IExpression {
+	return nil
+}
+
+func (e *BaseExpression) Base() api.IExpression {
+	return nil
+}
+
 // ---------------------------------------------------
 // Binary
 // ---------------------------------------------------
@@ -342,3 +350,147 @@ func (e *CallExpression) Paren() api.IToken {
 func (e *CallExpression) Arguments() []api.IExpression {
 	return e.arguments
 }
+
+// ---------------------------------------------------
+// Get ".", for example "std.printf"
+// ---------------------------------------------------
+type GetExpression struct {
+	BaseExpression
+
+	eType api.ExpressionType
+
+	object api.IExpression
+	name   api.IToken
+}
+
+func NewGetExpression(object api.IExpression, name api.IToken) api.IExpression {
+	e := new(GetExpression)
+	e.object = object
+	e.name = name
+	e.eType = api.GET_EXPR
+	return e
+}
+
+func (e *GetExpression) Accept(visitor api.IVisitorExpression) (obj interface{}, err api.IRuntimeError) {
+	return visitor.VisitGetExpression(e)
+}
+
+func (e *GetExpression) Object() api.IExpression {
+	return e.object
+}
+
+func (e *GetExpression) Name() api.IToken {
+	return e.name
+}
+
+func (e *GetExpression) Type() api.ExpressionType {
+	return e.eType
+}
+
+// ---------------------------------------------------
+// Numeric local label reference, for example "1b" or "1f"
+// ---------------------------------------------------
+type LocalLabelExpression struct {
+	BaseExpression
+
+	eType api.ExpressionType
+
+	name api.IToken
+}
+
+func NewLocalLabelExpression(name api.IToken) api.IExpression {
+	e := new(LocalLabelExpression)
+	e.name = name
+	e.eType = api.LOCAL_LABEL_EXPR
+	return e
+}
+
+func (e *LocalLabelExpression) Accept(visitor api.IVisitorExpression) (obj interface{}, err api.IRuntimeError) {
+	return visitor.VisitLocalLabelExpression(e)
+}
+
+func (e *LocalLabelExpression) Name() api.IToken {
+	return e.name
+}
+
+func (e *LocalLabelExpression) Type() api.ExpressionType {
+	return e.eType
+}
+
+// ---------------------------------------------------
+// Relocation operator, for example "%hi(hello)"
+// ---------------------------------------------------
+type RelocationExpression struct {
+	BaseExpression
+
+	eType api.ExpressionType
+
+	operator   api.IToken
+	expression api.IExpression
+}
+
+func NewRelocationExpression(operator api.IToken, expression api.IExpression) api.IExpression {
+	e := new(RelocationExpression)
+	e.operator = operator
+	e.expression = expression
+	e.eType = api.RELOCATION_EXPR
+	return e
+}
+
+func (e *RelocationExpression) Accept(visitor api.IVisitorExpression) (obj interface{}, err api.IRuntimeError) {
+	return visitor.VisitRelocationExpression(e)
+}
+
+func (e *RelocationExpression) Operator() api.IToken {
+	return e.operator
+}
+
+func (e *RelocationExpression) Expression() api.IExpression {
+	return e.expression
+}
+
+func (e *RelocationExpression) Type() api.ExpressionType {
+	return e.eType
+}
+
+// ---------------------------------------------------
+// Memory operand, for example "12(sp)" or "(a0)"
+// ---------------------------------------------------
+type MemoryExpression struct {
+	BaseExpression
+
+	eType api.ExpressionType
+
+	offset api.IExpression
+	paren  api.IToken
+	base   api.IExpression
+}
+
+func NewMemoryExpression(offset api.IExpression, paren api.IToken, base api.IExpression) api.IExpression {
+	e := new(MemoryExpression)
+	e.offset = offset
+	e.paren = paren
+	e.base = base
+	e.eType = api.MEMORY_EXPR
+	return e
+}
+
+func (e *MemoryExpression) Accept(visitor api.IVisitorExpression) (obj interface{}, err api.IRuntimeError) {
+	return visitor.VisitMemoryExpression(e)
+}
+
+func (e *MemoryExpression) Expression() api.IExpression {
+	return e.offset
+}
+
+func (e *MemoryExpression) Paren() api.IToken {
+	return e.paren
+}
+
+func (e *MemoryExpression) Base() api.IExpression {
+	return e.base
+}
+
+func (e *MemoryExpression) Type() api.ExpressionType {
+	return e.eType
+}
@@ -1,9 +1,12 @@
 package interpreter
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
 )
 
 // Some are FFIs and some are implementations
@@ -31,18 +34,232 @@ func (c ClockCallable) String() string {
 	return "<native fn>"
 }
 
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Defined, true if the config defines the named constant
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type DefinedCallable struct {
+	defines map[string]interface{}
+}
+
+func NewDefinedCallable(defines map[string]interface{}) api.ICallable {
+	o := new(DefinedCallable)
+	o.defines = defines
+	return o
+}
+
+func (c *DefinedCallable) Arity() int {
+	return 1
+}
+
+func (c *DefinedCallable) Call(interpreter api.IInterpreter, arguments []interface{}) (obj interface{}, err api.IRuntimeError) {
+	name, ok := arguments[0].(api.IStringLiteral)
+	if !ok {
+		return nil, errors.NewRuntimeError(nil, "'defined' expects the name of a define as a string.")
+	}
+
+	_, ok = c.defines[name.StringValue()]
+
+	return literals.NewBooleanLiteral(ok), nil
+}
+
+func (c DefinedCallable) String() string {
+	return "<native fn>"
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Assert, reports the message if the condition is false. It is
+// evaluated once the sections are laid out.
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type AssertCallable struct {
+}
+
+func NewAssertCallable() api.ICallable {
+	o := new(AssertCallable)
+	return o
+}
+
+func (c *AssertCallable) Arity() int {
+	return 2
+}
+
+func (c *AssertCallable) Call(interpreter api.IInterpreter, arguments []interface{}) (obj interface{}, err api.IRuntimeError) {
+	i := interpreter.(*Interpreter)
+
+	if !i.isTruthy(arguments[0]) {
+		i.assembler.ReportIn(i.source, i.call.Paren().Line(), "Assertion failed: "+messageOf(arguments[1]))
+	}
+
+	return nil, nil
+}
+
+func (c AssertCallable) String() string {
+	return "<native fn>"
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Error, reports the message and fails the build once the pass is done
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type ErrorCallable struct {
+}
+
+func NewErrorCallable() api.ICallable {
+	o := new(ErrorCallable)
+	return o
+}
+
+func (c *ErrorCallable) Arity() int {
+	return 1
+}
+
+func (c *ErrorCallable) Call(interpreter api.IInterpreter, arguments []interface{}) (obj interface{}, err api.IRuntimeError) {
+	i := interpreter.(*Interpreter)
+
+	i.assembler.ReportIn(i.source, i.call.Paren().Line(), messageOf(arguments[0]))
+
+	return nil, nil
+}
+
+func (c ErrorCallable) String() string {
+	return "<native fn>"
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Warning
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type WarningCallable struct {
+}
+
+func NewWarningCallable() api.ICallable {
+	o := new(WarningCallable)
+	return o
+}
+
+func (c *WarningCallable) Arity() int {
+	return 1
+}
+
+func (c *WarningCallable) Call(interpreter api.IInterpreter, arguments []interface{}) (obj interface{}, err api.IRuntimeError) {
+	i := interpreter.(*Interpreter)
+
+	i.assembler.ReportWarningIn(i.source, i.call.Paren().Line(), messageOf(arguments[0]))
+
+	return nil, nil
+}
+
+func (c WarningCallable) String() string {
+	return "<native fn>"
+}
+
+// A string message as is, anything else as it prints
+func messageOf(obj interface{}) string {
+	if text, ok := obj.(api.IStringLiteral); ok {
+		return text.StringValue()
+	}
+
+	return fmt.Sprint(obj)
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Sizeof, the size in bytes of a section, data element or struct
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type SizeofCallable struct {
+}
+
+func NewSizeofCallable() api.ICallable {
+	o := new(SizeofCallable)
+	return o
+}
+
+func (c *SizeofCallable) Arity() int {
+	return 1
+}
+
+func (c *SizeofCallable) Call(interpreter api.IInterpreter, arguments []interface{}) (obj interface{}, err api.IRuntimeError) {
+	// A struct's size is known as soon as it is declared
+	if layout, ok := arguments[0].(api.IStruct); ok {
+		return literals.NewIntegerLiteralVal(layout.Size()), nil
+	}
+
+	symbol, err := laidOutSymbol(interpreter, "sizeof", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol.Item() == nil {
+		return literals.NewIntegerLiteralVal(symbol.Section().Size()), nil
+	}
+
+	if symbol.Item().Kind() == api.ITEM_DATA {
+		return literals.NewIntegerLiteralVal(symbol.Item().Size()), nil
+	}
+
+	return nil, errors.NewRuntimeError(nil, "'sizeof' expects a section or data element, '"+symbol.Name().Lexeme()+"' is a label.")
+}
+
+func (c SizeofCallable) String() string {
+	return "<native fn>"
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// AddressOf, the address of a symbol
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type AddressOfCallable struct {
+}
+
+func NewAddressOfCallable() api.ICallable {
+	o := new(AddressOfCallable)
+	return o
+}
+
+func (c *AddressOfCallable) Arity() int {
+	return 1
+}
+
+func (c *AddressOfCallable) Call(interpreter api.IInterpreter, arguments []interface{}) (obj interface{}, err api.IRuntimeError) {
+	symbol, err := laidOutSymbol(interpreter, "addressOf", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return literals.NewIntegerLiteralVal(symbol.Address()), nil
+}
+
+func (c AddressOfCallable) String() string {
+	return "<native fn>"
+}
+
+// Sizes and addresses are only known once the sections are laid out
+func laidOutSymbol(interpreter api.IInterpreter, native string, obj interface{}) (symbol api.ISymbol, err api.IRuntimeError) {
+	i := interpreter.(*Interpreter)
+
+	if i.assembler.Pass() == 1 {
+		return nil, errors.NewRuntimeError(nil, "'"+native+"' is only known after layout. Use it in 'assert', an operand or a data value.")
+	}
+
+	symbol, ok := obj.(api.ISymbol)
+	if !ok {
+		return nil, errors.NewRuntimeError(nil, "'"+native+"' expects a symbol.")
+	}
+
+	return symbol, nil
+}
+
 // ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
 // Function
 // ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
 type FunctionCallable struct {
 	declaration api.IStatement
 	closure     api.IEnvironment
+	// The interpreter of the module that declared the function. Its
+	// globals and resolved locals apply to the body.
+	interpreter api.IInterpreter
 }
 
-func NewFunctionCallable(declaration api.IStatement, closure api.IEnvironment) api.ICallable {
+func NewFunctionCallable(declaration api.IStatement, closure api.IEnvironment, interpreter api.IInterpreter) api.ICallable {
 	o := new(FunctionCallable)
 	o.declaration = declaration
 	o.closure = closure
+	o.interpreter = interpreter
 	return o
 }
 
@@ -57,7 +274,16 @@ func (c *FunctionCallable) Call(interpreter api.IInterpreter, arguments []interf
 		environment.Define(parm.Lexeme(), arguments[i])
 	}
 
-	err = interpreter.ExecuteBlock(c.declaration.Body(), environment)
+	// Instructions in the body are emitted into the caller's section,
+	// even when the function belongs to another module.
+	if caller, ok := interpreter.(*Interpreter); ok {
+		owner := c.interpreter.(*Interpreter)
+		prevSection := owner.section
+		owner.section = caller.section
+		defer func() { owner.section = prevSection }()
+	}
+
+	err = c.interpreter.ExecuteBlock(c.declaration.Body(), environment)
 	if err != nil {
 		// The error may actually be a "return" control-flow Interrupt which means
 		// we just return the "return"'s value instead of interpreting
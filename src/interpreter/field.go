@@ -0,0 +1,24 @@
+package interpreter
+
+import (
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
+)
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// NestedField
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// A nested struct field, for example "Line.b". It is an integer, the
+// field's offset, whose own fields can be accessed too, so "Line.b.y"
+// is the offset of "b" plus that of "y".
+type NestedField struct {
+	api.IIntegerLiteral
+	layout api.IStruct
+}
+
+func NewNestedField(offset int, layout api.IStruct) *NestedField {
+	o := new(NestedField)
+	o.IIntegerLiteral = literals.NewIntegerLiteralVal(offset)
+	o.layout = layout
+	return o
+}
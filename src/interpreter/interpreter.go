@@ -3,28 +3,63 @@ package interpreter
 import (
 	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
 )
 
 type Interpreter struct {
+	assembler api.IAssembler
+
 	// This globals field holds a fixed
 	// reference to the outermost global environment.
 	globals api.IEnvironment
 	// A map that associates each syntax tree node with its resolved data.
 	locals      map[api.IExpression]int
 	environment api.IEnvironment
+
+	// The section instructions and labels are currently emitted into
+	section api.ISection
+	// The item whose operands are being evaluated, if any
+	item api.ISectionItem
+	// True while evaluating in a snapshot, which holds the global
+	// variables as they were
+	frozen bool
+
+	// The macro expansion in progress, if any
+	expansion *expansion
+
+	// The file being interpreted and the call being evaluated, where
+	// natives such as "assert" report
+	source string
+	call   api.IExpression
 }
 
-func NewInterpreter() api.IInterpreter {
+func NewInterpreter(assembler api.IAssembler) api.IInterpreter {
 	o := new(Interpreter)
+	o.assembler = assembler
 	o.configure()
 	return o
 }
 
 func (i *Interpreter) configure() (err api.IRuntimeError) {
-	i.globals = NewEnvironment()
-	i.environment = i.globals
+	// Natives live in their own scope enclosing the globals so a
+	// module's globals only hold what the module itself defines.
+	builtins := NewEnvironment()
+	builtins.Define("clock", NewClockCallable())
+	builtins.Define("assert", NewAssertCallable())
+	builtins.Define("error", NewErrorCallable())
+	builtins.Define("warning", NewWarningCallable())
+	builtins.Define("sizeof", NewSizeofCallable())
+	builtins.Define("addressOf", NewAddressOfCallable())
+
+	defines := i.assembler.Properties().Defines()
+	builtins.Define("defined", NewDefinedCallable(defines))
+
+	for name, value := range defines {
+		builtins.DefineConstant(name, value)
+	}
 
-	i.globals.Define("clock", NewClockCallable())
+	i.globals = NewEnvironmentEnclosing(builtins)
+	i.environment = i.globals
 
 	i.locals = map[api.IExpression]int{}
 
@@ -35,6 +70,15 @@ func (i *Interpreter) Globals() api.IEnvironment {
 	return i.globals
 }
 
+func (i *Interpreter) SetSource(path string) {
+	i.source = path
+}
+
+// IInterpreter interface method
+func (i *Interpreter) Source() string {
+	return i.source
+}
+
 // IInterpreter interface method
 func (i *Interpreter) Interpret(statements []api.IStatement) api.IRuntimeError {
 	for _, statement := range statements {
@@ -78,6 +122,73 @@ func (i *Interpreter) ExecuteBlock(statements []api.IStatement, parentEnv api.IE
 	return nil
 }
 
+func (i *Interpreter) Evaluate(section api.ISection, item api.ISectionItem, expr api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	prevEnv, prevSection, prevItem, prevFrozen := i.environment, i.section, i.item, i.frozen
+
+	i.environment, i.section, i.item, i.frozen = item.Environment(), section, item, true
+
+	obj, err = i.evaluate(expr)
+
+	i.environment, i.section, i.item, i.frozen = prevEnv, prevSection, prevItem, prevFrozen
+
+	return obj, err
+}
+
+// Copies the scopes an instruction is emitted in, so its operands see
+// the values variables had at that point, for example a loop counter.
+// Constants and symbols are left to the live globals because a symbol
+// may only be defined further on.
+func (i *Interpreter) snapshot() api.IEnvironment {
+	variables := NewEnvironmentEnclosing(i.globals)
+
+	for name, value := range i.globals.Values() {
+		if !i.globals.IsConstant(name) {
+			variables.Define(name, value)
+		}
+	}
+
+	return i.copyScopes(i.environment, variables)
+}
+
+// Copies the scopes up to the globals, which are replaced by the
+// given environment.
+func (i *Interpreter) copyScopes(environment api.IEnvironment, globals api.IEnvironment) api.IEnvironment {
+	if environment == nil || environment == i.globals {
+		return globals
+	}
+
+	scope := NewEnvironmentEnclosing(i.copyScopes(environment.Enclosing(), globals))
+
+	for name, value := range environment.Values() {
+		if environment.IsConstant(name) {
+			scope.DefineConstant(name, value)
+		} else {
+			scope.Define(name, value)
+		}
+	}
+
+	return scope
+}
+
+// Evaluates a call again once the sections are laid out, in a
+// snapshot of the scopes it was made in.
+func (i *Interpreter) deferCall(expr api.IExpression) {
+	environment := i.snapshot()
+
+	i.assembler.AfterLayout(func() {
+		prevEnv, prevFrozen := i.environment, i.frozen
+		i.environment, i.frozen = environment, true
+
+		_, err := i.evaluate(expr)
+
+		i.environment, i.frozen = prevEnv, prevFrozen
+
+		if err != nil {
+			i.assembler.ReportIn(i.source, err.Token().Line(), err.Message())
+		}
+	})
+}
+
 func (i *Interpreter) Resolve(expr api.IExpression, depth int) (err api.IRuntimeError) {
 	i.locals[expr] = depth
 	return nil
@@ -90,7 +201,20 @@ func (i *Interpreter) lookUpVariable(expr api.IExpression) (obj interface{}, err
 		return i.environment.GetAt(distance, name)
 	}
 
-	return i.globals.Get(name)
+	// A snapshot holds the global variables as they were when it was
+	// taken, in front of the live globals.
+	if i.frozen {
+		return i.environment.Get(name)
+	}
+
+	obj, err = i.globals.Get(name)
+	if err != nil {
+		// Names brought in by "use" live in a block scope the
+		// resolver doesn't know about.
+		return i.environment.Get(name)
+	}
+
+	return obj, nil
 }
 
 // ------------------------------------------------------------
@@ -112,15 +236,21 @@ func (i *Interpreter) extractNumbers(left, right interface{}, token api.IToken)
 		// One maybe an integer literal
 		var l, r float64
 		if !isNumL {
-			v, _ := left.(api.IIntegerLiteral)
-			l = float64(v.IntValue())
+			v, ok := i.integerOf(left)
+			if !ok {
+				return 0, 0, errors.NewRuntimeError(token, "Operands not suitable.")
+			}
+			l = float64(v)
 		} else {
 			l = lfv.NumValue()
 		}
 
 		if !isNumR {
-			v, _ := right.(api.IIntegerLiteral)
-			r = float64(v.IntValue())
+			v, ok := i.integerOf(right)
+			if !ok {
+				return 0, 0, errors.NewRuntimeError(token, "Operands not suitable.")
+			}
+			r = float64(v)
 		} else {
 			r = rfv.NumValue()
 		}
@@ -131,20 +261,31 @@ func (i *Interpreter) extractNumbers(left, right interface{}, token api.IToken)
 	return 0, 0, errors.NewRuntimeError(token, "Operands not suitable.")
 }
 
+// Integers include hex, binary and char literals. A symbol
+// stands for its address.
+func (i *Interpreter) integerOf(obj interface{}) (value int, ok bool) {
+	symbol, isSymbol := obj.(api.ISymbol)
+	if isSymbol {
+		return symbol.Address(), true
+	}
+
+	return literals.IntegerOf(obj)
+}
+
 func (i *Interpreter) extractInteger(expr interface{}, token api.IToken) (v int, err api.IRuntimeError) {
-	ev, isInt := expr.(api.IIntegerLiteral)
+	v, isInt := i.integerOf(expr)
 	if isInt {
-		return ev.IntValue(), nil
+		return v, nil
 	}
 
 	return 0, errors.NewRuntimeError(token, "Operand not suitable.")
 }
 
 func (i *Interpreter) extractIntegers(left, right interface{}, token api.IToken) (lv, rv int, err api.IRuntimeError) {
-	l, liok := left.(api.IIntegerLiteral)
-	r, riok := right.(api.IIntegerLiteral)
+	l, liok := i.integerOf(left)
+	r, riok := i.integerOf(right)
 	if liok && riok {
-		return l.IntValue(), r.IntValue(), nil
+		return l, r, nil
 	}
 
 	return 0, 0, errors.NewRuntimeError(token, "Operands not suitable.")
@@ -181,6 +322,33 @@ func (i *Interpreter) isTruthy(obj interface{}) bool {
 	}
 }
 
+// Compares strings, booleans, registers and nil. Values of different
+// kinds are never equal. ok is false if neither value is one of those
+// kinds, numbers are compared by the caller.
+func (i *Interpreter) equals(left, right interface{}) (equal bool, ok bool) {
+	switch l := left.(type) {
+	case api.IStringLiteral:
+		r, isString := right.(api.IStringLiteral)
+		return isString && l.StringValue() == r.StringValue(), true
+	case api.IBooleanLiteral:
+		r, isBool := right.(api.IBooleanLiteral)
+		return isBool && l.BoolValue() == r.BoolValue(), true
+	case api.IRegisterLiteral:
+		r, isRegister := right.(api.IRegisterLiteral)
+		return isRegister && l.RegisterValue() == r.RegisterValue() && l.IsFloat() == r.IsFloat(), true
+	case api.INilLiteral:
+		_, isNil := right.(api.INilLiteral)
+		return isNil, true
+	}
+
+	switch right.(type) {
+	case api.IStringLiteral, api.IBooleanLiteral, api.IRegisterLiteral, api.INilLiteral:
+		return false, true
+	}
+
+	return false, false
+}
+
 // func (i *Interpreter) isEqual(left, right interface{}) bool {
 // 	_, isNilL := left.(api.INilLiteral)
 // 	_, isNilR := right.(api.INilLiteral)
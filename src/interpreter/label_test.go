@@ -0,0 +1,60 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestLabels(t *testing.T) {
+	assembler := asmtest.Assemble(t, `{}`, `
+code text {
+start:
+1:  beqz a0, 1f
+    j 1b
+1:  nop
+    bnez a0, 1b
+    j start
+}
+`)
+
+	want := asmtest.MachineCode(t,
+		"00050463", // beqz a0, .+8
+		"ffdff06f", // j .-4
+		"00000013",
+		"fe051ee3", // bnez a0, .-4, the closest preceding 1:
+		"ff1ff06f", // j .-16
+	)
+
+	if image := assembler.Sections()[0].Image(); !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+
+	symbol, ok := assembler.Symbols().Lookup("start")
+	if !ok || symbol.Address() != 0 {
+		t.Error("'start' isn't at 0")
+	}
+}
+
+func TestLabelErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"redefined", "x: nop\nx: nop", "[main.S line 3] Error: Symbol 'x' already defined on line 2."},
+		{"no preceding", "j 1b\n1: nop", "[main.S line 2] Error: No preceding definition of local label '1:'."},
+		{"no following", "1: nop\nj 1f", "[main.S line 3] Error: No following definition of local label '1:'."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			failure := asmtest.Failure(t, `{}`, "code text {\n"+test.source+"\n}")
+			if !strings.Contains(failure, test.err) {
+				t.Errorf("got %q, want %q", failure, test.err)
+			}
+		})
+	}
+}
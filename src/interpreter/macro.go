@@ -0,0 +1,90 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Macro
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type Macro struct {
+	declaration api.IStatement
+	// The scope the macro was declared in
+	closure api.IEnvironment
+	// Expansions so far, which number the renamed labels
+	expansions int
+}
+
+func NewMacro(declaration api.IStatement, closure api.IEnvironment) *Macro {
+	o := new(Macro)
+	o.declaration = declaration
+	o.closure = closure
+	return o
+}
+
+func (m Macro) String() string {
+	return "<macro " + m.declaration.Name().Lexeme() + ">"
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Macro argument
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// An argument is substituted unevaluated so it can be a label defined
+// further on. It is evaluated where the parameter is used, in the
+// scope of the invocation.
+type macroArgument struct {
+	expression  api.IExpression
+	environment api.IEnvironment
+}
+
+func (a *macroArgument) evaluate(i *Interpreter) (obj interface{}, err api.IRuntimeError) {
+	prevEnv := i.environment
+	i.environment = a.environment
+
+	obj, err = i.evaluate(a.expression)
+
+	i.environment = prevEnv
+
+	return obj, err
+}
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Expansion
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// An expansion in progress
+type expansion struct {
+	// The macro's name at the invocation
+	invocation api.IToken
+	// The items of the body's labels, renamed for this expansion
+	labels map[api.IStatement]api.ISectionItem
+
+	enclosing *expansion
+}
+
+// The invocations that are expanding, innermost first
+func (e *expansion) invocations() (tokens []api.IToken) {
+	for ; e != nil; e = e.enclosing {
+		tokens = append(tokens, e.invocation)
+	}
+
+	return tokens
+}
+
+// The name a label of the body gets in the n-th expansion, for
+// example "push.2.done".
+func expandedName(invocation api.IToken, n int, label api.IToken) string {
+	return fmt.Sprintf("%s.%d.%s", invocation.Lexeme(), n, label.Lexeme())
+}
+
+// The renamed item of a label of the body being expanded
+func (e *expansion) label(statement api.IStatement) (item api.ISectionItem, ok bool) {
+	if e == nil {
+		return nil, false
+	}
+
+	item, ok = e.labels[statement]
+
+	return item, ok
+}
@@ -0,0 +1,66 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestMacroExpansion(t *testing.T) {
+	assembler := asmtest.Assemble(t, `{}`, `
+macro spin(reg, count) {
+    li reg, count
+loop:
+    addi reg, reg, -1
+    bnez reg, loop
+}
+code text {
+    spin(a0, 3)
+    spin(a1, 5)
+}
+`)
+
+	want := asmtest.MachineCode(t,
+		"00300513", // li a0, 3
+		"fff50513", // addi a0, a0, -1
+		"fe051ee3", // bnez a0, .-4
+		"00500593", // li a1, 5
+		"fff58593", // addi a1, a1, -1
+		"fe059ee3", // bnez a1, .-4, its own loop
+	)
+
+	if image := assembler.Sections()[0].Image(); !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+
+	// Each expansion has its own label
+	for name, address := range map[string]int{"spin.1.loop": 4, "spin.2.loop": 16} {
+		symbol, ok := assembler.Symbols().Lookup(name)
+		if !ok || symbol.Address() != address {
+			t.Errorf("'%s' isn't at %#x", name, address)
+		}
+	}
+}
+
+func TestMacroErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"in the body", "macro bad(x) {\n    addi a0, a0, x\n}\ncode text {\n    nop\n    bad(5000)\n}",
+			"[main.S line 2] Error: immediate 5000 does not fit in 12-bit signed field (in macro 'bad' invoked on line 6)"},
+		{"arguments", "macro m(x) {\n    nop\n}\ncode text {\n    m(1, 2)\n}",
+			"[main.S line 5] Error: Macro 'm' expects 1 arguments, got 2."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if failure := asmtest.Failure(t, `{}`, test.source); !strings.Contains(failure, test.err) {
+				t.Errorf("got %q, want %q", failure, test.err)
+			}
+		})
+	}
+}
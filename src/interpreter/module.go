@@ -0,0 +1,67 @@
+package interpreter
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+// Module
+// ~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---~~~---
+type Module struct {
+	name    string
+	path    string
+	globals api.IEnvironment
+}
+
+func NewModule(name, path string, globals api.IEnvironment) api.IModule {
+	o := new(Module)
+	o.name = name
+	o.path = path
+	o.globals = globals
+	return o
+}
+
+func (m *Module) Name() string {
+	return m.name
+}
+
+func (m *Module) Path() string {
+	return m.path
+}
+
+func (m *Module) Globals() api.IEnvironment {
+	return m.globals
+}
+
+func (m *Module) Export(name string) (obj interface{}, ok bool) {
+	obj, ok = m.globals.Values()[name]
+	if !ok || !m.exported(obj) {
+		return nil, false
+	}
+
+	return obj, true
+}
+
+func (m *Module) Exports() map[string]interface{} {
+	exports := map[string]interface{}{}
+
+	for name, obj := range m.globals.Values() {
+		if m.exported(obj) {
+			exports[name] = obj
+		}
+	}
+
+	return exports
+}
+
+// Everything defined at the module's top level is exported except
+// symbols that aren't "global".
+func (m *Module) exported(obj interface{}) bool {
+	if symbol, isSymbol := obj.(api.ISymbol); isSymbol {
+		return symbol.Binding() == api.BINDING_GLOBAL
+	}
+
+	return true
+}
+
+func (m Module) String() string {
+	return "<module " + m.name + ">"
+}
@@ -0,0 +1,68 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestPrivateSectionNames(t *testing.T) {
+	assembler, report, err := asmtest.Run(t, `{}`, map[string]string{
+		"main.S": `
+import { "lib" as lib }
+code helper { nop }
+`,
+		"lib.S": `code helper { ret }`,
+	})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, report)
+	}
+
+	if count := len(assembler.Sections()); count != 2 {
+		t.Errorf("got %d sections, want 2", count)
+	}
+}
+
+func TestDuplicateSectionNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"same module", map[string]string{
+			"main.S": "code helper { nop }\ndata helper { byte [1] }",
+		}},
+		{"global in both modules", map[string]string{
+			"main.S": "import { \"lib\" as lib }\n[global] code helper { nop }",
+			"lib.S":  "[global] code helper { ret }",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, report, err := asmtest.Run(t, `{}`, test.files)
+			if err == nil {
+				t.Fatal("assembled")
+			}
+
+			if !strings.Contains(report, "Section 'helper' already defined.") {
+				t.Errorf("got report %q", report)
+			}
+		})
+	}
+}
+
+// Errors in an imported module name its file
+func TestModuleErrorLocation(t *testing.T) {
+	_, report, err := asmtest.Run(t, `{}`, map[string]string{
+		"main.S": "import { \"lib\" as lib }\ncode text { nop }",
+		"lib.S":  "code helper {\n    ret\n    addi a0, a0, 5000\n}",
+	})
+	if err == nil {
+		t.Fatal("assembled")
+	}
+
+	if !strings.Contains(report, "[lib.S line 3] Error:") {
+		t.Errorf("got report %q", report)
+	}
+}
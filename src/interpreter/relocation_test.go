@@ -0,0 +1,37 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestRelocations(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+[at 0x1000] code text {
+    lui a0, %hi(value)
+    addi a0, a0, %lo(value)
+1:  auipc a1, %pcrel_hi(value)
+    addi a1, a1, %pcrel_lo(1b)
+    lui a2, %hi(0x12800)
+    addi a2, a2, %lo(0x12800)
+}
+[at 0x12345] data d { byte value }
+`)
+
+	want := asmtest.MachineCode(t,
+		"00012537", // lui a0, 0x12
+		"34550513", // addi a0, a0, 0x345
+		// value is 0x1133d from the auipc
+		"00011597", // auipc a1, 0x11
+		"33d58593", // addi a1, a1, 0x33d
+		// The low part is negative so the high part rounds up
+		"00013637", // lui a2, 0x13
+		"80060613", // addi a2, a2, -2048
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
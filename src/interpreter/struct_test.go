@@ -0,0 +1,32 @@
+package interpreter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestNestedStructField(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+struct Point { word x; half y; }
+struct Line { byte kind; Point a; Point b; }
+code text {
+  lw a0, Line.b.y(a1)
+  li a2, Line.a.x
+  li a3, Line.b
+}
+`)
+
+	// Point is 8 bytes at alignment 4, so a is at 4, b at 12 and b.y
+	// at 16
+	expected := asmtest.MachineCode(t,
+		"0105a503", // lw a0, 16(a1)
+		"00400613", // addi a2, zero, 4
+		"00c00693", // addi a3, zero, 12
+	)
+
+	if !bytes.Equal(image, expected) {
+		t.Errorf("got % x, want % x", image, expected)
+	}
+}
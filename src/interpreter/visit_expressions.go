@@ -2,10 +2,12 @@ package interpreter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/sections"
 )
 
 // -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ --
@@ -135,9 +137,9 @@ func (i *Interpreter) VisitBinaryExpression(exprV api.IExpression) (obj interfac
 
 		return nil, errors.NewRuntimeError(exprV.Operator(), "'<=' Unexpected reachable code.")
 	case api.BANG_EQUAL:
-		// if !i.isEqual(left, right) {
-		// 	return literals.NewBooleanLiteral(true), nil
-		// }
+		if equal, ok := i.equals(left, right); ok {
+			return literals.NewBooleanLiteral(!equal), nil
+		}
 
 		l, r, err := i.extractNumbers(left, right, exprV.Operator())
 		if err == nil {
@@ -153,9 +155,9 @@ func (i *Interpreter) VisitBinaryExpression(exprV api.IExpression) (obj interfac
 
 		return nil, errors.NewRuntimeError(exprV.Operator(), "'!=' Unexpected reachable code.")
 	case api.EQUAL_EQUAL:
-		// if i.isEqual(left, right) {
-		// 	return literals.NewBooleanLiteral(true), nil
-		// }
+		if equal, ok := i.equals(left, right); ok {
+			return literals.NewBooleanLiteral(equal), nil
+		}
 
 		l, r, err := i.extractNumbers(left, right, exprV.Operator())
 		if err == nil {
@@ -208,55 +210,43 @@ func (i *Interpreter) VisitBinaryExpression(exprV api.IExpression) (obj interfac
 
 		return nil, errors.NewRuntimeError(exprV.Operator(), "'+' Operands must be two numbers or two strings.")
 	case api.SLASH:
-		// Both operands will be converted to floats before division
-		lfv, isNumL := left.(api.INumberLiteral)
-		rfv, isNumR := right.(api.INumberLiteral)
+		// Integers divide as integers so they stay usable as
+		// addresses and immediates. Otherwise both operands are
+		// converted to floats before division.
+		il, ir, err := i.extractIntegers(left, right, exprV.Operator())
+		if err == nil {
+			if ir == 0 {
+				return nil, errors.NewRuntimeError(exprV.Operator(), "Division by zero.")
+			}
+			nl := literals.NewIntegerLiteralVal(il / ir)
+			return nl, nil
+		}
 
-		var l, r float64
-		if !isNumL {
-			v, _ := left.(api.IIntegerLiteral)
-			l = float64(v.IntValue())
-		} else {
-			l = lfv.NumValue()
+		l, r, err := i.extractNumbers(left, right, exprV.Operator())
+		if err == nil {
+			nl := literals.NewNumberLiteralVal(l / r)
+			return nl, nil
 		}
 
-		if !isNumR {
-			v, _ := right.(api.IIntegerLiteral)
-			r = float64(v.IntValue())
-		} else {
-			r = rfv.NumValue()
+		return nil, errors.NewRuntimeError(exprV.Operator(), "'/' Operands must be two numbers.")
+	case api.PIPE:
+		il, ir, err := i.extractIntegers(left, right, exprV.Operator())
+		if err == nil {
+			nl := literals.NewIntegerLiteralVal(il | ir)
+			return nl, nil
 		}
 
-		nl := literals.NewNumberLiteralVal(l / r)
-		return nl, nil
+		return nil, errors.NewRuntimeError(exprV.Operator(), "'|' Operands must be two integers.")
 	case api.STAR:
-		lfv, isNumL := left.(api.INumberLiteral)
-		rfv, isNumR := right.(api.INumberLiteral)
-		if isNumL || isNumR {
-			// One maybe an integer literal
-			var l, r float64
-			if !isNumL {
-				v, _ := left.(api.IIntegerLiteral)
-				l = float64(v.IntValue())
-			} else {
-				l = lfv.NumValue()
-			}
-
-			if !isNumR {
-				v, _ := right.(api.IIntegerLiteral)
-				r = float64(v.IntValue())
-			} else {
-				r = rfv.NumValue()
-			}
-
+		l, r, err := i.extractNumbers(left, right, exprV.Operator())
+		if err == nil {
 			nl := literals.NewNumberLiteralVal(l * r)
 			return nl, nil
 		}
 
-		liv, liok := left.(api.IIntegerLiteral)
-		riv, riok := right.(api.IIntegerLiteral)
-		if liok && riok {
-			nl := literals.NewIntegerLiteralVal(liv.IntValue() * riv.IntValue())
+		il, ir, err := i.extractIntegers(left, right, exprV.Operator())
+		if err == nil {
+			nl := literals.NewIntegerLiteralVal(il * ir)
 			return nl, nil
 		}
 
@@ -272,6 +262,11 @@ func (i *Interpreter) VisitVariableExpression(exprV api.IExpression) (obj interf
 	if err != nil {
 		return nil, err
 	}
+
+	if argument, ok := obj.(*macroArgument); ok {
+		return argument.evaluate(i)
+	}
+
 	return obj, nil
 }
 
@@ -305,6 +300,12 @@ func (i *Interpreter) VisitCallExpression(exprV api.IExpression) (obj interface{
 		return nil, err
 	}
 
+	// An assertion may be about the layout so it waits for it
+	if _, ok := callee.(*AssertCallable); ok && i.assembler.Pass() == 1 {
+		i.deferCall(exprV)
+		return literals.NewNilLiteral(), nil
+	}
+
 	arguments := make([]interface{}, 0) // = []interface{}{}
 
 	for _, argument := range exprV.Arguments() {
@@ -327,5 +328,188 @@ func (i *Interpreter) VisitCallExpression(exprV api.IExpression) (obj interface{
 
 	// The implementer’s job is
 	// to return the value that the call expression produces.
-	return function.Call(i, arguments)
+	prevCall := i.call
+	i.call = exprV
+
+	obj, err = function.Call(i, arguments)
+
+	i.call = prevCall
+
+	if err != nil && err.Token() == nil {
+		// Natives don't know where they were called from
+		return nil, errors.NewRuntimeError(exprV.Paren(), err.Message())
+	}
+
+	return obj, err
+}
+
+func (i *Interpreter) VisitGetExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	object, err := i.evaluate(exprV.Object())
+	if err != nil {
+		return nil, err
+	}
+
+	name := exprV.Name()
+
+	if module, ok := object.(api.IModule); ok {
+		obj, ok = module.Export(name.Lexeme())
+		if !ok {
+			return nil, errors.NewRuntimeError(name, "Module '"+module.Name()+"' doesn't export '"+name.Lexeme()+"'.")
+		}
+		return obj, nil
+	}
+
+	if enum, ok := object.(*Enum); ok {
+		obj, ok = enum.Member(name.Lexeme())
+		if !ok {
+			return nil, errors.NewRuntimeError(name, "'"+enum.name.Lexeme()+"' has no member '"+name.Lexeme()+"'.")
+		}
+		return obj, nil
+	}
+
+	// The fields of a nested field are offset by it
+	base := 0
+	if nested, ok := object.(*NestedField); ok {
+		base, object = nested.IntValue(), nested.layout
+	}
+
+	// A field stands for its offset, for example "lw a0, Point.y(a1)"
+	if layout, ok := object.(api.IStruct); ok {
+		field, ok := layout.Field(name.Lexeme())
+		if !ok {
+			return nil, errors.NewRuntimeError(name, "Struct '"+layout.Name().Lexeme()+"' has no field '"+name.Lexeme()+"'.")
+		}
+		if field.Struct() != nil {
+			return NewNestedField(base+field.Offset(), field.Struct()), nil
+		}
+		return literals.NewIntegerLiteralVal(base + field.Offset()), nil
+	}
+
+	return nil, errors.NewRuntimeError(name, "Only modules, structs and enums have properties.")
+}
+
+// "Nb" refers to the closest definition of "N:" at or before the current
+// item, "Nf" to the closest one after it.
+func (i *Interpreter) VisitLocalLabelExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	name := exprV.Name()
+
+	if i.item == nil {
+		return nil, errors.NewRuntimeError(name, "Local label '"+name.Lexeme()+"' can only be used as an instruction operand.")
+	}
+
+	label := name.Literal().String()
+	backward := strings.HasSuffix(name.Lexeme(), "b")
+
+	items := i.section.Items()
+
+	current := 0
+	for current < len(items) && items[current] != i.item {
+		current++
+	}
+
+	step := 1
+	if backward {
+		step = -1
+	}
+
+	for index := current + step; index >= 0 && index < len(items); index += step {
+		item := items[index]
+		if item.Kind() != api.ITEM_LABEL {
+			continue
+		}
+
+		token := item.Statement().Name()
+		if token.Type() == api.NUMBER && token.Literal().String() == label {
+			return sections.NewSymbol(token, i.section, item, api.BINDING_LOCAL), nil
+		}
+	}
+
+	direction := "following"
+	if backward {
+		direction = "preceding"
+	}
+
+	return nil, errors.NewRuntimeError(name, "No "+direction+" definition of local label '"+label+":'.")
+}
+
+// %hi is rounded so that adding the sign extended %lo gives back
+// the full value.
+func (i *Interpreter) VisitRelocationExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	operator := exprV.Operator()
+
+	value, err := i.evaluate(exprV.Expression())
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := i.extractInteger(value, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator.Type() {
+	case api.PCREL_HI:
+		if i.item == nil {
+			return nil, errors.NewRuntimeError(operator, "'%pcrel_hi' can only be used as an instruction operand.")
+		}
+		v -= i.section.Address() + i.item.Offset()
+	case api.PCREL_LO:
+		symbol, isSymbol := value.(api.ISymbol)
+		if !isSymbol {
+			return nil, errors.NewRuntimeError(operator, "'%pcrel_lo' expects the label of an 'auipc' instruction.")
+		}
+		v, err = i.pcrelOffset(operator, symbol)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch operator.Type() {
+	case api.HI, api.PCREL_HI:
+		v = ((v + 0x800) >> 12) & 0xfffff
+	case api.LO, api.PCREL_LO:
+		v = ((v & 0xfff) ^ 0x800) - 0x800
+	}
+
+	return literals.NewIntegerLiteralVal(v), nil
+}
+
+// pcrelOffset finds the "auipc" at the label and returns the offset
+// its %pcrel_hi operand was computed from.
+func (i *Interpreter) pcrelOffset(operator api.IToken, symbol api.ISymbol) (offset int, err api.IRuntimeError) {
+	section := symbol.Section()
+	address := symbol.Address()
+
+	for _, item := range section.Items() {
+		if item.Kind() != api.ITEM_INSTRUCTION || section.Address()+item.Offset() != address {
+			continue
+		}
+
+		statement := item.Statement()
+		operands := statement.Operands()
+		if statement.Mnemonic().Type() != api.AUIPC || len(operands) != 2 || operands[1].Type() != api.RELOCATION_EXPR ||
+			operands[1].Operator().Type() != api.PCREL_HI {
+			break
+		}
+
+		value, err := i.Evaluate(section, item, operands[1].Expression())
+		if err != nil {
+			return 0, err
+		}
+
+		target, err := i.extractInteger(value, operator)
+		if err != nil {
+			return 0, err
+		}
+
+		return target - address, nil
+	}
+
+	return 0, errors.NewRuntimeError(operator, "'%pcrel_lo' label '"+symbol.Name().Lexeme()+"' is not on an 'auipc' with a '%pcrel_hi' operand.")
+}
+
+// A memory operand is taken apart by the encoder; it has no value of
+// its own.
+func (i *Interpreter) VisitMemoryExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	return nil, errors.NewRuntimeError(exprV.Paren(), "A memory operand can only be the address of a load, store or 'jalr'.")
 }
@@ -0,0 +1,462 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/sections"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/statements"
+)
+
+// -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ --
+// IVisitorStatement implementations for assembly sections
+// -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ --
+func (i *Interpreter) VisitCodeSectionStatement(statement api.IStatement) (err api.IRuntimeError) {
+	section, err := i.newSection(statement, api.SECTION_CODE)
+	if err != nil {
+		return err
+	}
+
+	// Everything inside the section is emitted into it. The body
+	// gets its own scope so "use" only affects this section.
+	prevSection := i.section
+	i.section = section
+
+	err = i.ExecuteBlock(statement.Statements(), NewEnvironmentEnclosing(i.environment))
+
+	i.section = prevSection
+
+	return err
+}
+
+func (i *Interpreter) newSection(statement api.IStatement, kind api.SectionKind) (section api.ISection, err api.IRuntimeError) {
+	name := statement.Name()
+
+	section = sections.NewSection(name, kind, statement.Attributes(), i)
+
+	if i.sectionDefined(section) {
+		return nil, errors.NewRuntimeError(name, "Section '"+name.Lexeme()+"' already defined.")
+	}
+
+	err = i.locateSection(section)
+	if err != nil {
+		return nil, err
+	}
+
+	binding := api.BINDING_LOCAL
+	if section.Attributes().Global() {
+		binding = api.BINDING_GLOBAL
+	}
+
+	err = i.defineSymbol(sections.NewSymbol(name, section, nil, binding))
+	if err != nil {
+		return nil, err
+	}
+
+	i.assembler.AddSection(section)
+
+	return section, nil
+}
+
+// A section name is private to its module unless the section is
+// global, then it must be unique among the global ones.
+func (i *Interpreter) sectionDefined(section api.ISection) bool {
+	for _, other := range i.assembler.Sections() {
+		if other.Name().Lexeme() != section.Name().Lexeme() {
+			continue
+		}
+
+		if other.Interpreter() == i || other.Attributes().Global() && section.Attributes().Global() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Symbols are immutable names in the module's global scope so they can
+// be referenced from anywhere in the module and exported by "import".
+func (i *Interpreter) defineSymbol(symbol api.ISymbol) (err api.IRuntimeError) {
+	name := symbol.Name()
+
+	err = i.globals.DefineConstant(name.Lexeme(), symbol)
+	if err != nil {
+		if existing, ok := i.globals.Values()[name.Lexeme()].(api.ISymbol); ok {
+			msg := fmt.Sprintf("Symbol '%s' already defined on line %d.", name.Lexeme(), existing.Name().Line())
+			return errors.NewRuntimeError(name, msg)
+		}
+		return errors.NewRuntimeError(name, "'"+name.Lexeme()+"' already defined.")
+	}
+
+	i.assembler.Symbols().Add(symbol)
+
+	return nil
+}
+
+// Appends an item at the section's current location.
+func (i *Interpreter) emit(item api.ISectionItem) {
+	item.SetOffset(i.section.Size())
+	item.SetInvocations(i.expansion.invocations())
+	i.section.Append(item)
+}
+
+// Evaluates the section's "at" attribute, if present.
+func (i *Interpreter) locateSection(section api.ISection) (err api.IRuntimeError) {
+	at := section.Attributes().At()
+	if at == nil {
+		return nil
+	}
+
+	value, err := i.evaluate(at)
+	if err != nil {
+		return err
+	}
+
+	address, ok := literals.IntegerOf(value)
+	if !ok || address < 0 {
+		return errors.NewRuntimeError(section.Name(), "Section '"+section.Name().Lexeme()+"' 'at' address must be a positive integer.")
+	}
+
+	section.SetOrigin(address)
+
+	return nil
+}
+
+func (i *Interpreter) VisitInstructionStatement(statement api.IStatement) (err api.IRuntimeError) {
+	if i.section == nil {
+		return errors.NewRuntimeError(statement.Mnemonic(), "Instruction '"+statement.Mnemonic().Lexeme()+"' outside of a code section.")
+	}
+
+	// Operands are evaluated in pass 2, after loops have moved on, so
+	// they keep the values variables have now.
+	item := sections.NewItem(api.ITEM_INSTRUCTION, statement, i.snapshot(), i)
+	// Every base instruction is a word. The layout adjusts the size once
+	// the instruction is expanded.
+	item.SetSize(4)
+	i.emit(item)
+
+	return nil
+}
+
+func (i *Interpreter) VisitLabelStatement(statement api.IStatement) (err api.IRuntimeError) {
+	if i.section == nil {
+		return errors.NewRuntimeError(statement.Name(), "Label '"+statement.Name().Lexeme()+"' outside of a code section.")
+	}
+
+	// A label of a macro body was defined when the expansion began
+	if item, ok := i.expansion.label(statement); ok {
+		i.emit(item)
+		return nil
+	}
+
+	item := sections.NewItem(api.ITEM_LABEL, statement, i.environment, i)
+	i.emit(item)
+
+	// Numeric local labels can be redefined and are found by position instead.
+	if statement.Name().Type() == api.NUMBER {
+		return nil
+	}
+
+	return i.defineSymbol(sections.NewSymbol(statement.Name(), i.section, item, api.BINDING_LOCAL))
+}
+
+// Brings a module's exports into the current block scope.
+func (i *Interpreter) VisitUseStatement(statement api.IStatement) (err api.IRuntimeError) {
+	name := statement.Name()
+
+	obj, err := i.environment.Get(name)
+	if err != nil {
+		return err
+	}
+
+	module, ok := obj.(api.IModule)
+	if !ok {
+		return errors.NewRuntimeError(name, "'"+name.Lexeme()+"' is not a module.")
+	}
+
+	for export, value := range module.Exports() {
+		err = i.environment.DefineConstant(export, value)
+		if err != nil {
+			return errors.NewRuntimeError(name, "'use "+name.Lexeme()+"' conflicts with '"+export+"' already in scope.")
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitMacroStatement(statement api.IStatement) (err api.IRuntimeError) {
+	return i.environment.Define(statement.Name().Lexeme(), NewMacro(statement, i.environment))
+}
+
+// Expands a macro's body inline. The parameters stand for the
+// unevaluated arguments. The labels of the body are renamed for each
+// expansion and defined up front, so the body can refer to a label
+// further on.
+func (i *Interpreter) VisitMacroInvocationStatement(statement api.IStatement) (err api.IRuntimeError) {
+	name := statement.Name()
+
+	if i.section == nil {
+		return errors.NewRuntimeError(name, "Macro '"+name.Lexeme()+"' invoked outside of a code section.")
+	}
+
+	obj, err := i.evaluate(statement.Expression())
+	if err != nil {
+		return err
+	}
+
+	macro, ok := obj.(*Macro)
+	if !ok {
+		return errors.NewRuntimeError(name, "'"+name.Lexeme()+"' is not a macro.")
+	}
+
+	parameters := macro.declaration.Parameters()
+	arguments := statement.Operands()
+
+	if len(arguments) != len(parameters) {
+		msg := fmt.Sprintf("Macro '%s' expects %d arguments, got %d.", name.Lexeme(), len(parameters), len(arguments))
+		return errors.NewRuntimeError(name, msg)
+	}
+
+	environment := NewEnvironmentEnclosing(macro.closure)
+
+	invocationEnv := i.snapshot()
+	for index, parameter := range parameters {
+		environment.Define(parameter.Lexeme(), &macroArgument{arguments[index], invocationEnv})
+	}
+
+	macro.expansions++
+	expanding := &expansion{invocation: name, labels: map[api.IStatement]api.ISectionItem{}, enclosing: i.expansion}
+
+	for _, stmt := range macro.declaration.Body() {
+		label, isLabel := stmt.(*statements.LabelStatement)
+		if !isLabel || label.Name().Type() == api.NUMBER {
+			continue
+		}
+
+		renamed := scanner.NewToken(api.IDENTIFIER, expandedName(name, macro.expansions, label.Name()), nil, label.Name().Line())
+		item := sections.NewItem(api.ITEM_LABEL, statements.NewLabelStatement(renamed), environment, i)
+		symbol := sections.NewSymbol(renamed, i.section, item, api.BINDING_LOCAL)
+
+		err = environment.DefineConstant(label.Name().Lexeme(), symbol)
+		if err != nil {
+			return errors.NewRuntimeError(label.Name(), "Label '"+label.Name().Lexeme()+"' already defined in macro '"+name.Lexeme()+"'.")
+		}
+
+		i.assembler.Symbols().Add(symbol)
+		expanding.labels[stmt] = item
+	}
+
+	prevExpansion := i.expansion
+	i.expansion = expanding
+
+	err = i.ExecuteBlock(macro.declaration.Body(), environment)
+
+	i.expansion = prevExpansion
+
+	if err != nil && err.Interrupt() == api.INTERRUPT_UNKNOWN {
+		return errors.NewRuntimeError(err.Token(), err.Message()+errors.InMacro(name))
+	}
+
+	return err
+}
+
+func (i *Interpreter) VisitDataSectionStatement(statement api.IStatement) (err api.IRuntimeError) {
+	section, err := i.newSection(statement, api.SECTION_DATA)
+	if err != nil {
+		return err
+	}
+
+	prevSection := i.section
+	i.section = section
+
+	for _, element := range statement.Statements() {
+		err = i.execute(element)
+		if err != nil {
+			break
+		}
+	}
+
+	i.section = prevSection
+
+	return err
+}
+
+// Reserves a data element's space, placed at its natural alignment.
+// Strings are compiled into their image straight away.
+func (i *Interpreter) VisitDataElementStatement(statement api.IStatement) (err api.IRuntimeError) {
+	keyword := statement.Keyword()
+
+	if i.section == nil || i.section.Kind() != api.SECTION_DATA {
+		return errors.NewRuntimeError(keyword, "Data element '"+keyword.Lexeme()+"' outside of a data section.")
+	}
+
+	size := statement.ElementSize()
+	alignment := size
+	bytes := []byte{}
+
+	if statement.Expression() != nil {
+		layout, err := i.structType(statement.Expression())
+		if err != nil {
+			return err
+		}
+
+		err = i.checkFields(layout, statement.Names())
+		if err != nil {
+			return err
+		}
+
+		// The values are placed in pass 2 like any other data values
+		bytes = make([]byte, layout.Size())
+		alignment = layout.Alignment()
+	} else if keyword.Type() == api.STRING_TYPE {
+		value, err := i.evaluate(statement.Initializers()[0])
+		if err != nil {
+			return err
+		}
+
+		text, ok := value.(api.IStringLiteral)
+		if !ok {
+			return errors.NewRuntimeError(keyword, "Expected a string value.")
+		}
+
+		unescaped, err := i.unescape(keyword, text.StringValue())
+		if err != nil {
+			return err
+		}
+
+		// Strings are null terminated
+		bytes = append([]byte(unescaped), 0)
+	} else if len(statement.Initializers()) == 0 {
+		bytes = make([]byte, size)
+	} else {
+		// The values may refer to symbols defined later so they are
+		// only evaluated in pass 2.
+		bytes = make([]byte, size*len(statement.Initializers()))
+	}
+
+	item := sections.NewItem(api.ITEM_DATA, statement, i.environment, i)
+	item.SetOffset(sections.Align(i.section.Size(), alignment))
+	item.SetBytes(bytes)
+
+	if statement.Name() != nil {
+		binding := api.BINDING_LOCAL
+		if statement.Global() {
+			binding = api.BINDING_GLOBAL
+		}
+
+		err = i.defineSymbol(sections.NewSymbol(statement.Name(), i.section, item, binding))
+		if err != nil {
+			return err
+		}
+	}
+
+	i.section.Append(item)
+
+	return nil
+}
+
+func (i *Interpreter) VisitStructStatement(statement api.IStatement) (err api.IRuntimeError) {
+	layout := sections.NewStruct(statement.Name())
+
+	for _, field := range statement.Statements() {
+		name := field.Name()
+
+		if _, ok := layout.Field(name.Lexeme()); ok {
+			return errors.NewRuntimeError(name, "Field '"+name.Lexeme()+"' is already declared in struct '"+statement.Name().Lexeme()+"'.")
+		}
+
+		if field.Expression() == nil {
+			layout.AddField(name, field.ElementSize(), field.ElementSize(), nil)
+			continue
+		}
+
+		nested, err := i.structType(field.Expression())
+		if err != nil {
+			return err
+		}
+
+		layout.AddField(name, nested.Size(), nested.Alignment(), nested)
+	}
+
+	err = i.environment.DefineConstant(statement.Name().Lexeme(), layout)
+	if err != nil {
+		return errors.NewRuntimeError(statement.Name(), err.Message())
+	}
+
+	return nil
+}
+
+// Evaluates the type of a struct element or field
+func (i *Interpreter) structType(expression api.IExpression) (layout api.IStruct, err api.IRuntimeError) {
+	obj, err := i.evaluate(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, ok := obj.(api.IStruct)
+	if !ok {
+		return nil, errors.NewRuntimeError(expression.Name(), "'"+expression.Name().Lexeme()+"' is not a struct.")
+	}
+
+	return layout, nil
+}
+
+// The initialized fields must be the struct's own and scalar
+func (i *Interpreter) checkFields(layout api.IStruct, names []api.IToken) (err api.IRuntimeError) {
+	for n, name := range names {
+		field, ok := layout.Field(name.Lexeme())
+		if !ok {
+			return errors.NewRuntimeError(name, "Struct '"+layout.Name().Lexeme()+"' has no field '"+name.Lexeme()+"'.")
+		}
+
+		if field.Struct() != nil {
+			return errors.NewRuntimeError(name, "Field '"+name.Lexeme()+"' is a struct and can't be given a value.")
+		}
+
+		for _, previous := range names[:n] {
+			if previous.Lexeme() == name.Lexeme() {
+				return errors.NewRuntimeError(name, "Field '"+name.Lexeme()+"' is given more than one value.")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Replaces the escape sequences of a string value.
+func (i *Interpreter) unescape(token api.IToken, text string) (unescaped string, err api.IRuntimeError) {
+	escaped := false
+
+	for _, c := range text {
+		if !escaped {
+			if c == '\\' {
+				escaped = true
+			} else {
+				unescaped += string(c)
+			}
+			continue
+		}
+
+		escaped = false
+
+		switch c {
+		case 'n':
+			unescaped += "\n"
+		case 't':
+			unescaped += "\t"
+		case 'r':
+			unescaped += "\r"
+		case '0':
+			unescaped += "\x00"
+		case '\\', '\'', '"':
+			unescaped += string(c)
+		default:
+			return "", errors.NewRuntimeError(token, "Unknown escape sequence '\\"+string(c)+"'.")
+		}
+	}
+
+	return unescaped, nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
 	"github.com/wdevore/RISCV-Meta-Assembler/src/errors"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
 )
 
 // -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ --
@@ -39,6 +41,113 @@ func (i *Interpreter) VisitVariableStatement(statement api.IStatement) (err api.
 	return i.environment.Define(statement.Name().Lexeme(), value)
 }
 
+// Constants declared with "csr" name custom CSRs and must be 12-bit
+// CSR numbers.
+func (i *Interpreter) VisitConstStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for c, name := range statement.Names() {
+		value, err := i.evaluate(statement.Initializers()[c])
+		if err != nil {
+			return err
+		}
+
+		if statement.Keyword().Type() == api.CSR {
+			err = i.checkCSR(name, value)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = i.environment.DefineConstant(name.Lexeme(), value)
+		if err != nil {
+			return errors.NewRuntimeError(name, err.Message())
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) checkCSR(name api.IToken, value interface{}) api.IRuntimeError {
+	if _, ok := scanner.CSRs[name.Lexeme()]; ok {
+		return errors.NewRuntimeError(name, "'"+name.Lexeme()+"' is already a CSR.")
+	}
+
+	number, ok := literals.IntegerOf(value)
+	if !ok || number < 0 || number > 0xfff {
+		return errors.NewRuntimeError(name, fmt.Sprintf("CSR number of '%s' must be an integer from 0 to 0xfff.", name.Lexeme()))
+	}
+
+	return nil
+}
+
+// Enum members count up from the previous one, starting at 0. Flags
+// count bits instead, so "= 22" makes a member 1<<22.
+func (i *Interpreter) VisitEnumStatement(statement api.IStatement) (err api.IRuntimeError) {
+	name := statement.Name()
+	isFlags := statement.Keyword().Type() != api.ENUM
+
+	enum := NewEnum(name)
+	next := 0
+
+	for m, member := range statement.Names() {
+		if _, ok := enum.Member(member.Lexeme()); ok {
+			return errors.NewRuntimeError(member, "Member '"+member.Lexeme()+"' is already declared in '"+name.Lexeme()+"'.")
+		}
+
+		if initializer := statement.Initializers()[m]; initializer != nil {
+			obj, err := i.evaluate(initializer)
+			if err != nil {
+				return err
+			}
+
+			value, ok := literals.IntegerOf(obj)
+			if !ok {
+				return errors.NewRuntimeError(member, "Value of '"+member.Lexeme()+"' must be an integer.")
+			}
+
+			next = value
+		}
+
+		value := next
+		if isFlags {
+			if next < 0 || next > 31 {
+				return errors.NewRuntimeError(member, "Bit of flag '"+member.Lexeme()+"' must be between 0 and 31.")
+			}
+
+			value = 1 << next
+		}
+
+		enum.members[member.Lexeme()] = literals.NewIntegerLiteralVal(value)
+		next++
+	}
+
+	err = i.environment.DefineConstant(name.Lexeme(), enum)
+	if err != nil {
+		return errors.NewRuntimeError(name, err.Message())
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitImportStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for m, path := range statement.Paths() {
+		literal, _ := path.Literal().(api.IStringLiteral)
+
+		module, errI := i.assembler.Import(literal.StringValue())
+		if errI != nil {
+			return errors.NewRuntimeError(path, errI.Error())
+		}
+
+		alias := statement.Names()[m]
+
+		err = i.environment.DefineConstant(alias.Lexeme(), module)
+		if err != nil {
+			return errors.NewRuntimeError(alias, err.Message())
+		}
+	}
+
+	return nil
+}
+
 func (i *Interpreter) VisitBlockStatement(statement api.IStatement) (err api.IRuntimeError) {
 	childEnv := NewEnvironmentEnclosing(i.environment)
 	return i.ExecuteBlock(statement.Statements(), childEnv)
@@ -115,7 +224,7 @@ func (i *Interpreter) VisitFunctionStatement(statement api.IStatement) (err api.
 	// The environment that is active when the function is declared not when it’s called
 	// It represents the lexical scope surrounding the
 	// function declaration.
-	function := NewFunctionCallable(statement, i.environment)
+	function := NewFunctionCallable(statement, i.environment, i)
 	return i.environment.Define(statement.Name().Lexeme(), function)
 }
 
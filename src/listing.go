@@ -0,0 +1,120 @@
+package src
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// Bytes of data shown per listing line
+const listingDataWidth = 4
+
+// Listing shows every section with the address and machine code of
+// each item followed by the symbol table. A pseudo instruction is
+// shown next to the base instructions it expanded into and a
+// compressed one next to its compressed form. A code section with
+// compressed instructions ends with the bytes they saved.
+func (a *Assembler) Listing() string {
+	var builder strings.Builder
+
+	for _, section := range a.sections {
+		fmt.Fprintf(&builder, "%s section %s @ %#08x\n", section.Kind(), section.Name().Lexeme(), section.Address())
+
+		compressed := 0
+
+		for _, item := range section.Items() {
+			address := section.Address() + item.Offset()
+
+			switch item.Kind() {
+			case api.ITEM_LABEL:
+				fmt.Fprintf(&builder, "%08x            %s:\n", address, item.Statement().Name().Lexeme())
+			case api.ITEM_INSTRUCTION:
+				compressed += a.listInstruction(&builder, address, item)
+			case api.ITEM_DATA:
+				a.listData(&builder, address, item)
+			}
+		}
+
+		if compressed > 0 {
+			fmt.Fprintf(&builder, "%d instruction(s) compressed, %d bytes saved\n", compressed, 2*compressed)
+		}
+
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("symbols\n")
+	for _, symbol := range a.symbols.Symbols() {
+		fmt.Fprintf(&builder, "%08x  %-6s  %-12s  %s\n", symbol.Address(), symbol.Binding(), symbol.Section().Name().Lexeme(), symbol.Name().Lexeme())
+	}
+
+	return builder.String()
+}
+
+// Lists an instruction item a line per instruction and returns how
+// many of them are compressed.
+func (a *Assembler) listInstruction(builder *strings.Builder, address int, item api.ISectionItem) (compressed int) {
+	bytes := item.Bytes()
+	expansion := item.Expansion()
+	text := item.Statement().Text()
+
+	pseudo := len(expansion) > 0
+
+	if len(bytes) == 0 {
+		// The lui of a relaxed "%hi"/"%lo" pair, otherwise an
+		// instruction that failed to assemble
+		reason := "(not encoded)"
+		if item.Relaxed() && item.Size() == 0 {
+			reason = "(relaxed away)"
+		}
+		fmt.Fprintf(builder, "%08x  %-8s      %-28s %s\n", address, "", text, reason)
+		return 0
+	}
+
+	for index, start := 0, 0; start < len(bytes); index++ {
+		// The low two bits of a 32-bit instruction are set
+		width := 4
+		if bytes[start]&0x3 != 0x3 {
+			width = 2
+			compressed++
+		}
+
+		word := 0
+		for b := width - 1; b >= 0; b-- {
+			word = word<<8 | int(bytes[start+b])
+		}
+		code := fmt.Sprintf("%0*x", 2*width, word)
+
+		switch {
+		case !pseudo:
+			fmt.Fprintf(builder, "%08x  %-8s      %s\n", address, code, text)
+		case index == 0:
+			fmt.Fprintf(builder, "%08x  %-8s      %-28s %s\n", address, code, text, expansion[index])
+		default:
+			fmt.Fprintf(builder, "%08x  %-8s      %-28s %s\n", address, code, "", expansion[index])
+		}
+
+		start += width
+		address += width
+	}
+
+	return compressed
+}
+
+func (a *Assembler) listData(builder *strings.Builder, address int, item api.ISectionItem) {
+	bytes := item.Bytes()
+	name := ""
+	if item.Statement().Name() != nil {
+		name = item.Statement().Name().Lexeme()
+	}
+
+	for start := 0; start < len(bytes); start += listingDataWidth {
+		end := start + listingDataWidth
+		if end > len(bytes) {
+			end = len(bytes)
+		}
+
+		fmt.Fprintf(builder, "%08x  %-12s  %s\n", address+start, fmt.Sprintf("% x", bytes[start:end]), name)
+		name = ""
+	}
+}
@@ -0,0 +1,99 @@
+package src
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/interpreter"
+)
+
+// Extensions tried, in order, when an import names a file without one.
+var moduleExtensions = []string{"", ".S", ".s"}
+
+func (a *Assembler) Import(name string) (module api.IModule, err error) {
+	path, err := a.findModule(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := a.modules[path]; ok {
+		return module, nil
+	}
+
+	for l, loading := range a.loading {
+		if loading == path {
+			cycle := []string{}
+			for _, file := range append(a.loading[l:], path) {
+				cycle = append(cycle, filepath.Base(file))
+			}
+			return nil, fmt.Errorf("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	// Each module gets its own interpreter and therefore its own globals.
+	modInterpreter := interpreter.NewInterpreter(a)
+
+	a.loading = append(a.loading, path)
+
+	_, err = a.load(path, modInterpreter)
+
+	a.loading = a.loading[:len(a.loading)-1]
+
+	if err != nil {
+		return nil, fmt.Errorf("module '%s' (%s): %v", name, path, err)
+	}
+
+	module = interpreter.NewModule(name, path, modInterpreter.Globals())
+	a.modules[path] = module
+
+	return module, nil
+}
+
+// The absolute path of a source relative to the config directory.
+func (a *Assembler) sourcePath(source string) (path string, err error) {
+	if filepath.IsAbs(source) {
+		return source, nil
+	}
+
+	dataPath, err := filepath.Abs(a.configRelPath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataPath, source), nil
+}
+
+// Searches the config directory and then each configured search path.
+func (a *Assembler) findModule(name string) (path string, err error) {
+	configPath, err := filepath.Abs(a.configRelPath)
+	if err != nil {
+		return "", err
+	}
+
+	directories := []string{configPath}
+
+	if a.properties != nil {
+		for _, searchPath := range a.properties.SearchPaths() {
+			if !filepath.IsAbs(searchPath) {
+				searchPath = filepath.Join(configPath, searchPath)
+			}
+			directories = append(directories, searchPath)
+		}
+	}
+
+	for _, directory := range directories {
+		for _, extension := range moduleExtensions {
+			path = filepath.Join(directory, name+extension)
+
+			info, err := os.Stat(path)
+			if err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("module '%s' not found in: %s", name, strings.Join(directories, ", "))
+}
@@ -0,0 +1,37 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestReservedDataNames(t *testing.T) {
+	tests := []struct {
+		element string
+		report  string
+	}{
+		{"word j [1]", "'j' is a reserved mnemonic and can't be used as a name."},
+		{"byte add", "'add' is a reserved mnemonic and can't be used as a name."},
+		{"half sp [1]", "'sp' is a register and can't be used as a name."},
+		{"Point ret { x = 1 }", "'ret' is a reserved mnemonic and can't be used as a name."},
+		{"word [1", "Expect ']' after data values."},
+		{"word", "Expect a name or values for data element."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.element, func(t *testing.T) {
+			_, report, err := asmtest.Run(t, `{}`, map[string]string{
+				"main.S": "struct Point { word x; }\ndata d {\n    " + test.element + "\n}\n",
+			})
+			if err == nil {
+				t.Fatal("assembled")
+			}
+
+			if !strings.Contains(report, test.report) {
+				t.Errorf("got report %q, want %q", report, test.report)
+			}
+		})
+	}
+}
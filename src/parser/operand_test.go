@@ -0,0 +1,67 @@
+package parser_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+func TestMemoryOperands(t *testing.T) {
+	image := asmtest.Image(t, `{}`, `
+code text {
+    var r = a1;
+    lw a0, 12(sp)
+    lw a0, (a0)
+    sw a1, -4(a0)
+    jalr ra, 8(t0)
+    lw a0, 2*4(sp)
+    lw a0, 4(r)
+    lw a0, %lo(v)(a1)
+}
+[at 0x1234] data d { word v }
+`)
+
+	want := asmtest.MachineCode(t,
+		"00c12503",
+		"00052503",
+		"feb52e23",
+		"008280e7",
+		"00812503",
+		"0045a503",
+		"2345a503",
+	)
+
+	if !bytes.Equal(image, want) {
+		t.Errorf("got % x, want % x", image, want)
+	}
+}
+
+func TestMemoryOperandErrors(t *testing.T) {
+	tests := []struct {
+		operand string
+		report  string
+	}{
+		// Reported on the operand's line, not at the "}" after it
+		{"lw a0, 4(a1", "[line 2] Error  at 'a1' : Expect ')' after base register 'a1'."},
+		{"lw a0, 4(", "[line 2] Error  at '(' : Expect base register after '('."},
+		{"lw a0, 4 a1", "[line 2] Error  at 'a1' : Expect '(' before base register 'a1'."},
+		{"lw a0, a1(a2)", "[line 2] Error  at 'a1' : Register 'a1' can't be used as an offset."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.operand, func(t *testing.T) {
+			_, report, err := asmtest.Run(t, `{}`, map[string]string{
+				"main.S": "code text {\n    " + test.operand + "\n}\n",
+			})
+			if err == nil {
+				t.Fatal("assembled")
+			}
+
+			if !strings.Contains(report, test.report) {
+				t.Errorf("got report %q, want %q", report, test.report)
+			}
+		})
+	}
+}
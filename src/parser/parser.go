@@ -13,12 +13,23 @@ type Parser struct {
 	assembler api.IAssembler
 	tokens    []api.IToken
 	current   int
+
+	// True while parsing an instruction operand, where "(" followed
+	// by a register starts a memory operand rather than a call.
+	inOperand bool
+	// The line of the operand's mnemonic
+	operandLine int
+
+	// Names of the macros declared so far. An invocation looks like a
+	// call but is a statement of its own, like an instruction.
+	macros map[string]bool
 }
 
 func NewParser(assembler api.IAssembler, tokens []api.IToken) *Parser {
 	o := new(Parser)
 	o.tokens = tokens
 	o.assembler = assembler
+	o.macros = map[string]bool{}
 	return o
 }
 
@@ -41,6 +52,42 @@ func (p *Parser) declaration() (expr api.IStatement, err error) {
 		return p.function("function")
 	}
 
+	if p.match(api.MACRO) {
+		statement, err := p.macroDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	if p.match(api.LEFT_BRACKET) {
+		statement, err := p.attributedSection()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	if p.match(api.CODE) {
+		statement, err := p.codeSection(statements.NewAttributes())
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	if p.match(api.DATA) {
+		statement, err := p.dataSection(statements.NewAttributes())
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
 	if p.match(api.VAR) {
 		statement, err := p.varDeclaration()
 		if err != nil {
@@ -50,6 +97,69 @@ func (p *Parser) declaration() (expr api.IStatement, err error) {
 		return statement, err
 	}
 
+	if p.match(api.IMPORT) {
+		statement, err := p.importDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	if p.match(api.STRUCT) {
+		statement, err := p.structDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	// "flags" is only a keyword at the start of a declaration so it can
+	// still name a field or a variable.
+	isFlags := p.check(api.IDENTIFIER) && p.peek().Lexeme() == "flags" && p.checkNext(api.IDENTIFIER)
+
+	if isFlags || p.check(api.ENUM) {
+		p.advance()
+
+		statement, err := p.enumDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	if p.match(api.CONST, api.CSR) {
+		statement, err := p.constDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+		return statement, err
+	}
+
+	// Named labels and numeric local labels, for example "loop:" or "1:"
+	if (p.check(api.IDENTIFIER) || p.check(api.NUMBER)) && p.checkNext(api.COLON) {
+		return p.labelStatement()
+	}
+
+	if p.check(api.REGISTER) && p.checkNext(api.COLON) {
+		err = p.reserved(p.peek())
+		p.synchronize()
+		return nil, err
+	}
+
+	if p.check(api.IDENTIFIER) && p.checkNext(api.LEFT_PAREN) && p.macros[p.peek().Lexeme()] {
+		return p.macroInvocation()
+	}
+
+	// Instructions can appear anywhere a statement can. They are emitted
+	// into the code section that is executing when they are reached.
+	if p.peek().Type().IsInstruction() {
+		return p.instruction()
+	}
+
 	return p.statement()
 }
 
@@ -99,12 +209,35 @@ func (p *Parser) function(kind string) (expr api.IStatement, err error) {
 		return nil, err
 	}
 
+	parameters, err := p.parameters(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// We consume the { at the beginning of the body here before calling
+	// block(). Because block() assumes the brace token has already been
+	// matched.
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before '"+kind+"' body.")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewFunctionStatement(funName, parameters, body), nil
+}
+
+// Parses "(" parameters? ")" of a function or macro.
+func (p *Parser) parameters(kind string) (parameters []api.IToken, err error) {
 	_, err = p.consume(api.LEFT_PAREN, "Expect '(' after '"+kind+"' name.")
 	if err != nil {
 		return nil, err
 	}
 
-	parameters := []api.IToken{}
+	parameters = []api.IToken{}
 
 	if !p.check(api.RIGHT_PAREN) {
 		for matchComma := true; matchComma; matchComma = p.match(api.COMMA) {
@@ -126,20 +259,7 @@ func (p *Parser) function(kind string) (expr api.IStatement, err error) {
 		return nil, err
 	}
 
-	// We consume the { at the beginning of the body here before calling
-	// block(). Because block() assumes the brace token has already been
-	// matched.
-	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before '"+kind+"' body.")
-	if err != nil {
-		return nil, err
-	}
-
-	body, err := p.block()
-	if err != nil {
-		return nil, err
-	}
-
-	return statements.NewFunctionStatement(funName, parameters, body), nil
+	return parameters, nil
 }
 
 func (p *Parser) expression() (expr api.IExpression, err error) {
@@ -185,7 +305,7 @@ func (p *Parser) or() (expr api.IExpression, err error) {
 		return nil, err
 	}
 
-	for p.match(api.OR) {
+	for p.matchOperator(api.OR) {
 		operator := p.previous()
 
 		right, err := p.and()
@@ -206,7 +326,7 @@ func (p *Parser) and() (expr api.IExpression, err error) {
 		return nil, err
 	}
 
-	for p.match(api.AND) {
+	for p.matchOperator(api.AND) {
 		operator := p.previous()
 
 		right, err := p.equality()
@@ -245,6 +365,146 @@ func (p *Parser) varDeclaration() (expr api.IStatement, err error) {
 	return statements.NewVarStatement(name, initializer), nil
 }
 
+// The parser has already matched the "import" token.
+// For example: import { "stdio" as std }
+func (p *Parser) importDeclaration() (expr api.IStatement, err error) {
+	keyword := p.previous()
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' after 'import'.")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []api.IToken{}
+	aliases := []api.IToken{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		path, err := p.consume(api.STRING, "Expect module path string.")
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.consume(api.AS, "Expect 'as' after module path.")
+		if err != nil {
+			return nil, err
+		}
+
+		alias, err := p.consume(api.IDENTIFIER, "Expect module alias after 'as'.")
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, path)
+		aliases = append(aliases, alias)
+
+		if !p.match(api.COMMA) {
+			break
+		}
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after imports.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewImportStatement(keyword, paths, aliases), nil
+}
+
+// The parser has already matched the "const" or "csr" token.
+// For example: const { GPIO_BASE = 0x10012000, GPIO_RED = 0x400000 }
+// "csr" declares constants that name custom CSRs, for example
+// csr { mdebug = 0x7c0 }
+func (p *Parser) constDeclaration() (expr api.IStatement, err error) {
+	keyword := p.previous()
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' after '"+keyword.Lexeme()+"'.")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []api.IToken{}
+	initializers := []api.IExpression{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		name, err := p.consume(api.IDENTIFIER, "Expect constant name.")
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.consume(api.EQUAL, "Expect '=' after constant name.")
+		if err != nil {
+			return nil, err
+		}
+
+		initializer, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+		initializers = append(initializers, initializer)
+
+		if !p.match(api.COMMA) {
+			break
+		}
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after constants.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewConstStatement(keyword, names, initializers), nil
+}
+
+// "enum Color { RED, GREEN = 5, BLUE }" or "flags Irq { TIMER, UART }".
+// A member without a value follows the previous one.
+func (p *Parser) enumDeclaration() (expr api.IStatement, err error) {
+	keyword := p.previous()
+
+	name, err := p.consume(api.IDENTIFIER, "Expect '"+keyword.Lexeme()+"' name.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' after '"+keyword.Lexeme()+"' name.")
+	if err != nil {
+		return nil, err
+	}
+
+	members := []api.IToken{}
+	initializers := []api.IExpression{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		member, err := p.consume(api.IDENTIFIER, "Expect member name.")
+		if err != nil {
+			return nil, err
+		}
+
+		var initializer api.IExpression
+		if p.match(api.EQUAL) {
+			initializer, err = p.expression()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		members = append(members, member)
+		initializers = append(initializers, initializer)
+
+		if !p.match(api.COMMA) {
+			break
+		}
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after members.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewEnumStatement(keyword, name, members, initializers), nil
+}
+
 // --------------------------------------------------------
 // equality
 // --------------------------------------------------------
@@ -254,7 +514,7 @@ func (p *Parser) equality() (expr api.IExpression, err error) {
 		return nil, err
 	}
 
-	for p.match(api.BANG_EQUAL, api.EQUAL_EQUAL) {
+	for p.matchOperator(api.BANG_EQUAL, api.EQUAL_EQUAL) {
 		operator := p.previous()
 		right, errc := p.comparison()
 		if errc != nil {
@@ -269,6 +529,17 @@ func (p *Parser) equality() (expr api.IExpression, err error) {
 // This checks to see if the current token has any of the given types.
 // If so, it consumes the token and returns true.
 // Otherwise, it returns false and leaves the current token alone
+// Matches a binary operator. In an instruction operand it must be on
+// the mnemonic's line since the next line may start with the "or" or
+// "and" instruction.
+func (p *Parser) matchOperator(types ...api.TokenType) bool {
+	if p.inOperand && p.peek().Line() != p.operandLine {
+		return false
+	}
+
+	return p.match(types...)
+}
+
 func (p *Parser) match(types ...api.TokenType) bool {
 	for _, ttype := range types {
 		if p.check(ttype) {
@@ -290,6 +561,15 @@ func (p *Parser) check(ttype api.TokenType) bool {
 	return p.peek().Type() == ttype
 }
 
+// returns true if the token after the current one is of the given type
+func (p *Parser) checkNext(ttype api.TokenType) bool {
+	if p.isAtEnd() || p.current+1 >= len(p.tokens) {
+		return false
+	}
+
+	return p.tokens[p.current+1].Type() == ttype
+}
+
 // consumes the current token and returns it, similar to
 // how our scanner’s corresponding method crawled through characters
 func (p *Parser) advance() api.IToken {
@@ -324,7 +604,7 @@ func (p *Parser) comparison() (expr api.IExpression, err error) {
 		return nil, err
 	}
 
-	for p.match(api.GREATER, api.GREATER_EQUAL, api.LESS, api.LESS_EQUAL) {
+	for p.matchOperator(api.GREATER, api.GREATER_EQUAL, api.LESS, api.LESS_EQUAL) {
 		operator := p.previous()
 		right, err := p.term()
 		if err != nil {
@@ -345,7 +625,7 @@ func (p *Parser) term() (expr api.IExpression, err error) {
 		return nil, err
 	}
 
-	for p.match(api.MINUS, api.PLUS) {
+	for p.matchOperator(api.MINUS, api.PLUS, api.PIPE) {
 		operator := p.previous()
 		right, err := p.factor()
 		if err != nil {
@@ -366,7 +646,7 @@ func (p *Parser) factor() (expr api.IExpression, err error) {
 		return nil, err
 	}
 
-	for p.match(api.SLASH, api.STAR) {
+	for p.matchOperator(api.SLASH, api.STAR) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -406,13 +686,22 @@ func (p *Parser) call() (expr api.IExpression, err error) {
 	for {
 		// Each time we see a "("" , we call finishCall() to parse the call expression using the
 		// previously parsed expression as the callee
-		if p.match(api.LEFT_PAREN) {
+		if p.inOperand && p.check(api.LEFT_PAREN) && (p.checkNext(api.REGISTER) || expr.Type() == api.LITERAL_EXPR) {
+			// Left for operand() as the base register
+			break
+		} else if p.match(api.LEFT_PAREN) {
 			// The returned expression becomes the
 			// new expr and we loop to see if the result is itself called.
 			expr, err = p.finishCall(expr)
 			if err != nil {
 				return nil, err
 			}
+		} else if p.match(api.DOT) {
+			name, err := p.consume(api.IDENTIFIER, "Expect property name after '.'.")
+			if err != nil {
+				return nil, err
+			}
+			expr = interpreter.NewGetExpression(expr, name)
 		} else {
 			break
 		}
@@ -464,6 +753,18 @@ func (p *Parser) primary() (expr api.IExpression, err error) {
 		return interpreter.NewLiteralExpression(p.previous(), p.previous().Literal()), nil
 	}
 
+	if p.match(api.PERCENT) {
+		return p.relocation()
+	}
+
+	if p.match(api.REGISTER) {
+		return interpreter.NewLiteralExpression(p.previous(), p.previous().Literal()), nil
+	}
+
+	if p.match(api.LOCAL_LABEL) {
+		return interpreter.NewLocalLabelExpression(p.previous()), nil
+	}
+
 	// Parsing a variable expression
 	if p.match(api.IDENTIFIER) {
 		return interpreter.NewVariableExpression(p.previous()), nil
@@ -486,6 +787,35 @@ func (p *Parser) primary() (expr api.IExpression, err error) {
 	return nil, p.lerror(p.previous(), "Expected expression to begin.")
 }
 
+// The parser has already matched the "%".
+// For example: %hi(hello) or %pcrel_lo(1b)
+func (p *Parser) relocation() (expr api.IExpression, err error) {
+	operator := p.advance()
+
+	switch operator.Type() {
+	case api.HI, api.LO, api.PCREL_HI, api.PCREL_LO:
+	default:
+		return nil, p.lerror(operator, "Expect 'hi', 'lo', 'pcrel_hi' or 'pcrel_lo' after '%'.")
+	}
+
+	_, err = p.consume(api.LEFT_PAREN, "Expect '(' after '%"+operator.Lexeme()+"'.")
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err = p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.RIGHT_PAREN, "Expect ')' after relocation expression.")
+	if err != nil {
+		return nil, err
+	}
+
+	return interpreter.NewRelocationExpression(operator, expr), nil
+}
+
 // --------------------------------------------------------
 // Blocks
 // --------------------------------------------------------
@@ -517,9 +847,29 @@ func (p *Parser) consume(ttype api.TokenType, message string) (token api.IToken,
 	}
 
 	token = p.peek()
+
+	if ttype == api.IDENTIFIER {
+		if err = p.reserved(token); err != nil {
+			return token, err
+		}
+	}
+
 	return token, p.lerror(token, message)
 }
 
+// Registers and mnemonics are reserved, they can't be used where a name
+// is expected.
+func (p *Parser) reserved(token api.IToken) error {
+	switch {
+	case token.Type() == api.REGISTER:
+		return p.lerror(token, "'"+token.Lexeme()+"' is a register and can't be used as a name.")
+	case token.Type().IsInstruction():
+		return p.lerror(token, "'"+token.Lexeme()+"' is a reserved mnemonic and can't be used as a name.")
+	}
+
+	return nil
+}
+
 func (p *Parser) lerror(ttype api.IToken, message string) error {
 	p.assembler.ReportToken(ttype, message)
 	return errors.New(message)
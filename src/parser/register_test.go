@@ -0,0 +1,30 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/internal/asmtest"
+)
+
+// Registers are reserved and can't name anything
+func TestRegisterNames(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string
+	}{
+		{"var sp = 1;", "[line 1] Error  at 'sp' : 'sp' is a register and can't be used as a name."},
+		{"const { a0 = 1 }", "[line 1] Error  at 'a0' : 'a0' is a register and can't be used as a name."},
+		{"fun f(t0) {}", "[line 1] Error  at 't0' : 't0' is a register and can't be used as a name."},
+		{"code text {\nra: nop\n}", "[line 2] Error  at 'ra' : 'ra' is a register and can't be used as a name."},
+		{"var ret = 1;", "[line 1] Error  at 'ret' : 'ret' is a reserved mnemonic and can't be used as a name."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			if failure := asmtest.Failure(t, `{}`, test.source); !strings.Contains(failure, test.err) {
+				t.Errorf("got %q, want %q", failure, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,729 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/interpreter"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/statements"
+)
+
+// --------------------------------------------------------
+// Attribute list, for example "[alignTo word, global]"
+// --------------------------------------------------------
+// The list applies to the section that directly follows it.
+func (p *Parser) attributedSection() (statement api.IStatement, err error) {
+	attributes, err := p.attributes()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(api.CODE) {
+		return p.codeSection(attributes)
+	}
+
+	if p.match(api.DATA) {
+		return p.dataSection(attributes)
+	}
+
+	return nil, p.lerror(p.peek(), "Expect section after attribute list.")
+}
+
+func (p *Parser) attributes() (attributes *statements.Attributes, err error) {
+	attributes = statements.NewAttributes()
+	seen := map[api.TokenType]bool{}
+
+	if !p.check(api.RIGHT_BRACKET) {
+		for matchComma := true; matchComma; matchComma = p.match(api.COMMA) {
+			attribute := p.advance()
+
+			if seen[attribute.Type()] {
+				return nil, p.lerror(attribute, "Duplicate attribute '"+attribute.Lexeme()+"'.")
+			}
+			seen[attribute.Type()] = true
+
+			switch attribute.Type() {
+			case api.ALIGN_TO:
+				alignment, err := p.alignment()
+				if err != nil {
+					return nil, err
+				}
+				attributes.SetAlignment(alignment)
+			case api.GLOBAL:
+				attributes.SetGlobal(true)
+			case api.AT:
+				at, err := p.expression()
+				if err != nil {
+					return nil, err
+				}
+				attributes.SetAt(at)
+			case api.READ_ONLY:
+				if seen[api.READ_WRITE] {
+					return nil, p.lerror(attribute, "'readOnly' conflicts with 'readWrite'.")
+				}
+				attributes.SetAccess(api.ACCESS_READ_ONLY)
+			case api.READ_WRITE:
+				if seen[api.READ_ONLY] {
+					return nil, p.lerror(attribute, "'readWrite' conflicts with 'readOnly'.")
+				}
+				attributes.SetAccess(api.ACCESS_READ_WRITE)
+			case api.RELAX:
+				if seen[api.NO_RELAX] {
+					return nil, p.lerror(attribute, "'relax' conflicts with 'noRelax'.")
+				}
+				attributes.SetRelax(api.RELAX_ENABLED)
+			case api.NO_RELAX:
+				if seen[api.RELAX] {
+					return nil, p.lerror(attribute, "'noRelax' conflicts with 'relax'.")
+				}
+				attributes.SetRelax(api.RELAX_DISABLED)
+			case api.NO_COMPRESS:
+				attributes.SetCompress(false)
+			default:
+				return nil, p.lerror(attribute, "Unknown section attribute.")
+			}
+		}
+	}
+
+	_, err = p.consume(api.RIGHT_BRACKET, "Expect ']' after attribute list.")
+	if err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+// Parses the alignment that follows "alignTo" and returns it in bytes.
+func (p *Parser) alignment() (alignment int, err error) {
+	if p.match(api.WORD) {
+		return 4, nil
+	}
+
+	if p.match(api.HALF) {
+		return 2, nil
+	}
+
+	if p.match(api.BYTE) {
+		return 1, nil
+	}
+
+	if !p.match(api.BYTES) {
+		return 0, p.lerror(p.peek(), "Expect 'word', 'half', 'byte' or 'bytes<N>' after 'alignTo'.")
+	}
+
+	// Either "bytes<N>" or "bytes(N)". The scanner drops a "<" that is
+	// directly followed by a digit so it is optional here.
+	closing := api.GREATER
+	if p.match(api.LEFT_PAREN) {
+		closing = api.RIGHT_PAREN
+	} else {
+		p.match(api.LESS)
+	}
+
+	size, err := p.consume(api.NUMBER, "Expect alignment size after 'bytes'.")
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = p.consume(closing, "Expect '"+closing.String()+"' after alignment size.")
+	if err != nil {
+		return 0, err
+	}
+
+	alignment, ok := literals.IntegerOf(size.Literal())
+	if !ok || alignment <= 0 || alignment&(alignment-1) != 0 {
+		return 0, p.lerror(size, fmt.Sprintf("Alignment '%s' is not a power of two.", size.Lexeme()))
+	}
+
+	return alignment, nil
+}
+
+// --------------------------------------------------------
+// "code" section
+// --------------------------------------------------------
+func (p *Parser) codeSection(attributes api.IAttributes) (statement api.IStatement, err error) {
+	name, err := p.consume(api.IDENTIFIER, "Expect section name after 'code'.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before code section body.")
+	if err != nil {
+		return nil, err
+	}
+
+	body := []api.IStatement{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		stmt, err := p.codeStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		body = append(body, stmt)
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after code section.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewCodeSectionStatement(name, attributes, body), nil
+}
+
+// A code section contains "use" statements and declarations, which
+// include instructions and labels.
+func (p *Parser) codeStatement() (statement api.IStatement, err error) {
+	if p.match(api.USE) {
+		return p.useStatement()
+	}
+
+	if p.check(api.CODE) || p.check(api.DATA) || p.check(api.LEFT_BRACKET) {
+		return nil, p.lerror(p.peek(), "Sections can't be nested.")
+	}
+
+	return p.declaration()
+}
+
+// --------------------------------------------------------
+// "use" statement
+// --------------------------------------------------------
+func (p *Parser) useStatement() (statement api.IStatement, err error) {
+	keyword := p.previous()
+
+	name, err := p.consume(api.IDENTIFIER, "Expect module name after 'use'.")
+	if err != nil {
+		return nil, err
+	}
+
+	p.match(api.SEMICOLON)
+
+	return statements.NewUseStatement(keyword, name), nil
+}
+
+// --------------------------------------------------------
+// "macro" declaration
+// --------------------------------------------------------
+// The body is parsed like the body of a code section.
+func (p *Parser) macroDeclaration() (statement api.IStatement, err error) {
+	name, err := p.consume(api.IDENTIFIER, "Expect 'macro' name.")
+	if err != nil {
+		return nil, err
+	}
+
+	parameters, err := p.parameters("macro")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before 'macro' body.")
+	if err != nil {
+		return nil, err
+	}
+
+	// Declared before the body so the body can invoke it
+	p.macros[name.Lexeme()] = true
+
+	body := []api.IStatement{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		stmt, err := p.codeStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		body = append(body, stmt)
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after 'macro' body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewMacroStatement(name, parameters, body), nil
+}
+
+// A macro invocation, for example "push(s0)". Like an instruction it
+// takes an optional ";".
+func (p *Parser) macroInvocation() (statement api.IStatement, err error) {
+	name := p.advance()
+	p.advance()
+
+	arguments := []api.IExpression{}
+
+	if !p.check(api.RIGHT_PAREN) {
+		for matchComma := true; matchComma; matchComma = p.match(api.COMMA) {
+			argument, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+
+			arguments = append(arguments, argument)
+		}
+	}
+
+	_, err = p.consume(api.RIGHT_PAREN, "Expect ')' after macro arguments.")
+	if err != nil {
+		return nil, err
+	}
+
+	p.match(api.SEMICOLON)
+
+	return statements.NewMacroInvocationStatement(interpreter.NewVariableExpression(name), arguments), nil
+}
+
+// --------------------------------------------------------
+// Label, for example "loop:"
+// --------------------------------------------------------
+func (p *Parser) labelStatement() (statement api.IStatement, err error) {
+	name := p.advance()
+
+	if name.Type() == api.NUMBER {
+		if _, ok := name.Literal().(api.IIntegerLiteral); !ok {
+			return nil, p.lerror(name, "Local label must be a decimal number.")
+		}
+	}
+
+	_, err = p.consume(api.COLON, "Expect ':' after label name.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewLabelStatement(name), nil
+}
+
+// --------------------------------------------------------
+// Instruction
+// --------------------------------------------------------
+// Instructions are not terminated by ";". Instead the operands must
+// be on the same line as the mnemonic. An optional ";" allows several
+// instructions on one line.
+func (p *Parser) instruction() (statement api.IStatement, err error) {
+	start := p.current
+	mnemonic := p.advance()
+
+	operands := []api.IExpression{}
+
+	if p.onLine(mnemonic.Line()) {
+		for matchComma := true; matchComma; matchComma = p.match(api.COMMA) {
+			operand, err := p.operand(mnemonic)
+			if err != nil {
+				return nil, err
+			}
+
+			operands = append(operands, operand)
+		}
+	}
+
+	text := p.text(start, p.current)
+
+	p.match(api.SEMICOLON)
+
+	return statements.NewInstructionStatement(mnemonic, operands, text), nil
+}
+
+// Rebuilds the source text of tokens [from, to) with a space between
+// the first token and the rest and after each comma.
+func (p *Parser) text(from, to int) string {
+	var builder strings.Builder
+
+	for index := from; index < to; index++ {
+		if index == from+1 || index > from+1 && p.tokens[index-1].Type() == api.COMMA {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(p.tokens[index].Lexeme())
+	}
+
+	return builder.String()
+}
+
+// An operand is an expression optionally followed by a base register
+// in parentheses, for example "12(sp)", "%lo(hello)(a1)" or "(a0)".
+func (p *Parser) operand(mnemonic api.IToken) (expr api.IExpression, err error) {
+	p.inOperand, p.operandLine = true, mnemonic.Line()
+	defer func() { p.inOperand = false }()
+
+	var offset api.IExpression
+
+	if !(p.check(api.LEFT_PAREN) && p.checkNext(api.REGISTER)) {
+		offset, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.check(api.REGISTER) && p.onLine(mnemonic.Line()) {
+		register := p.peek()
+		return nil, p.lerror(register, "Expect '(' before base register '"+register.Lexeme()+"'.")
+	}
+
+	if !p.check(api.LEFT_PAREN) {
+		return offset, nil
+	}
+
+	if p.isRegister(offset) {
+		register := offset.Name()
+		return nil, p.lerror(register, "Register '"+register.Lexeme()+"' can't be used as an offset.")
+	}
+
+	paren := p.advance()
+
+	// The base is a register or a variable holding one.
+	var base api.IExpression
+
+	if p.match(api.REGISTER) {
+		base = interpreter.NewLiteralExpression(p.previous(), p.previous().Literal())
+	} else if p.match(api.IDENTIFIER) {
+		base = interpreter.NewVariableExpression(p.previous())
+	} else {
+		return nil, p.lerror(paren, "Expect base register after '('.")
+	}
+
+	// Reported at the base register, the next token may be lines on
+	if !p.match(api.RIGHT_PAREN) {
+		register := p.previous()
+		return nil, p.lerror(register, "Expect ')' after base register '"+register.Lexeme()+"'.")
+	}
+
+	return interpreter.NewMemoryExpression(offset, paren, base), nil
+}
+
+func (p *Parser) isRegister(expr api.IExpression) bool {
+	return expr != nil && expr.Type() == api.LITERAL_EXPR && expr.Name() != nil && expr.Name().Type() == api.REGISTER
+}
+
+// returns true if the current token continues the given line
+func (p *Parser) onLine(line int) bool {
+	if p.isAtEnd() {
+		return false
+	}
+
+	token := p.peek()
+
+	return token.Line() == line && token.Type() != api.SEMICOLON && token.Type() != api.RIGHT_BRACE
+}
+
+// --------------------------------------------------------
+// "data" section
+// --------------------------------------------------------
+func (p *Parser) dataSection(attributes api.IAttributes) (statement api.IStatement, err error) {
+	name, err := p.consume(api.IDENTIFIER, "Expect section name after 'data'.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before data section body.")
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := p.dataElements()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after data section.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewDataSectionStatement(name, attributes, elements), nil
+}
+
+// Data elements and conditional data up to a "}"
+func (p *Parser) dataElements() (elements []api.IStatement, err error) {
+	elements = []api.IStatement{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		var element api.IStatement
+
+		if p.match(api.IF) {
+			element, err = p.dataIf()
+		} else {
+			element, err = p.dataElement()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, element)
+
+		// Elements are separated by an optional ","
+		p.match(api.COMMA)
+	}
+
+	return elements, nil
+}
+
+// Conditional data, for example "if (DEBUG) { byte [1] } else { ... }".
+// The branches hold data elements instead of statements.
+func (p *Parser) dataIf() (statement api.IStatement, err error) {
+	_, err = p.consume(api.LEFT_PAREN, "Expect '(' after 'if'.")
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.RIGHT_PAREN, "Expect ')' after 'if' condition.")
+	if err != nil {
+		return nil, err
+	}
+
+	thenBranch, err := p.dataBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var elseBranch api.IStatement
+	if p.match(api.ELSE) {
+		if p.match(api.IF) {
+			elseBranch, err = p.dataIf()
+		} else {
+			elseBranch, err = p.dataBlock()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return statements.NewIfStatement(condition, thenBranch, elseBranch), nil
+}
+
+func (p *Parser) dataBlock() (statement api.IStatement, err error) {
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before conditional data.")
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := p.dataElements()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after conditional data.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewBlockStatement(elements), nil
+}
+
+// For example: "string hello "Hello"", "byte [1, 2]" or "global int<4> count"
+func (p *Parser) dataElement() (statement api.IStatement, err error) {
+	global := p.match(api.GLOBAL)
+
+	keyword := p.advance()
+
+	if keyword.Type() == api.IDENTIFIER {
+		return p.structElement(keyword, global)
+	}
+
+	size, err := p.scalarSize(keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		return nil, p.lerror(keyword, "Expect data type 'string', 'char', 'byte', 'half', 'word', 'int<N>', 'float', 'double' or a struct name.")
+	}
+
+	var name api.IToken
+	if p.match(api.IDENTIFIER) {
+		name = p.previous()
+	} else if err := p.reserved(p.peek()); err != nil {
+		return nil, err
+	}
+
+	initializers := []api.IExpression{}
+
+	if keyword.Type() == api.STRING_TYPE {
+		text, err := p.consume(api.STRING, "Expect string value after 'string'.")
+		if err != nil {
+			return nil, err
+		}
+
+		initializers = append(initializers, interpreter.NewLiteralExpression(text, text.Literal()))
+	} else if p.match(api.LEFT_BRACKET) {
+		for !p.check(api.RIGHT_BRACKET) && !p.isAtEnd() {
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+
+			initializers = append(initializers, value)
+
+			if !p.match(api.COMMA) {
+				break
+			}
+		}
+
+		_, err = p.consume(api.RIGHT_BRACKET, "Expect ']' after data values.")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if name == nil && len(initializers) == 0 {
+		return nil, p.lerror(keyword, "Expect a name or values for data element.")
+	}
+
+	return statements.NewDataElementStatement(keyword, name, global, size, initializers), nil
+}
+
+// The size of a scalar data type given its keyword, 0 if it isn't one
+func (p *Parser) scalarSize(keyword api.IToken) (size int, err error) {
+	switch keyword.Type() {
+	case api.STRING_TYPE, api.CHAR, api.BYTE:
+		return 1, nil
+	case api.HALF:
+		return 2, nil
+	case api.WORD, api.FLOAT:
+		return 4, nil
+	case api.DOUBLE:
+		return 8, nil
+	case api.INT:
+		return p.integerSize()
+	}
+
+	return 0, nil
+}
+
+// A data element of a struct type, for example
+// "Point origin { x = 0, y = 5 }". Fields without a value are zero.
+func (p *Parser) structElement(structName api.IToken, global bool) (statement api.IStatement, err error) {
+	var name api.IToken
+	if p.match(api.IDENTIFIER) {
+		name = p.previous()
+	} else if err := p.reserved(p.peek()); err != nil {
+		return nil, err
+	}
+
+	fields := []api.IToken{}
+	initializers := []api.IExpression{}
+
+	if p.match(api.LEFT_BRACE) {
+		for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+			field, err := p.consume(api.IDENTIFIER, "Expect field name.")
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = p.consume(api.EQUAL, "Expect '=' after field name.")
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, field)
+			initializers = append(initializers, value)
+
+			if !p.match(api.COMMA) {
+				break
+			}
+		}
+
+		_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after field values.")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	structType := interpreter.NewVariableExpression(structName)
+
+	return statements.NewStructElementStatement(structType, name, global, fields, initializers), nil
+}
+
+// "struct Point { word x; word y; half flags; }"
+func (p *Parser) structDeclaration() (statement api.IStatement, err error) {
+	name, err := p.consume(api.IDENTIFIER, "Expect 'struct' name.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(api.LEFT_BRACE, "Expect '{' before 'struct' fields.")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []api.IStatement{}
+
+	for !p.check(api.RIGHT_BRACE) && !p.isAtEnd() {
+		keyword := p.advance()
+
+		var field api.IStatement
+
+		if keyword.Type() == api.IDENTIFIER {
+			fieldName, err := p.consume(api.IDENTIFIER, "Expect field name.")
+			if err != nil {
+				return nil, err
+			}
+
+			structType := interpreter.NewVariableExpression(keyword)
+			field = statements.NewStructElementStatement(structType, fieldName, false, nil, nil)
+		} else {
+			size, err := p.scalarSize(keyword)
+			if err != nil {
+				return nil, err
+			}
+
+			// Field values are integers
+			if size == 0 || keyword.Type() == api.STRING_TYPE || keyword.Type() == api.FLOAT || keyword.Type() == api.DOUBLE {
+				return nil, p.lerror(keyword, "Expect field type 'char', 'byte', 'half', 'word', 'int<N>' or a struct name.")
+			}
+
+			fieldName, err := p.consume(api.IDENTIFIER, "Expect field name.")
+			if err != nil {
+				return nil, err
+			}
+
+			field = statements.NewDataElementStatement(keyword, fieldName, false, size, nil)
+		}
+
+		_, err = p.consume(api.SEMICOLON, "Expect ';' after field.")
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	_, err = p.consume(api.RIGHT_BRACE, "Expect '}' after 'struct' fields.")
+	if err != nil {
+		return nil, err
+	}
+
+	return statements.NewStructStatement(name, fields), nil
+}
+
+// Parses the "<N>" of "int<N>" and returns N.
+func (p *Parser) integerSize() (size int, err error) {
+	// The scanner drops a "<" that is directly followed by a digit.
+	p.match(api.LESS)
+
+	sizeToken, err := p.consume(api.NUMBER, "Expect size after 'int'.")
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = p.consume(api.GREATER, "Expect '>' after integer size.")
+	if err != nil {
+		return 0, err
+	}
+
+	size, ok := literals.IntegerOf(sizeToken.Literal())
+	if !ok || (size != 1 && size != 2 && size != 4 && size != 8) {
+		return 0, p.lerror(sizeToken, "Integer size must be 1, 2, 4 or 8 bytes.")
+	}
+
+	return size, nil
+}
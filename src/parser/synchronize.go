@@ -11,6 +11,10 @@ func (p *Parser) synchronize() {
 			return
 		}
 
+		if p.peek().Type().IsInstruction() {
+			return
+		}
+
 		switch p.peek().Type() {
 		case api.CONST,
 			api.IMPORT,
@@ -20,82 +24,33 @@ func (p *Parser) synchronize() {
 			api.AT,
 			api.AS,
 			api.USE,
+			api.MACRO,
+			api.STRUCT,
+			api.ENUM,
+			api.CSR,
 			api.READ_ONLY,
+			api.READ_WRITE,
+			api.RELAX,
+			api.NO_RELAX,
+			api.NO_COMPRESS,
+			api.LEFT_BRACKET,
 			api.BYTE,
 			api.HALF,
 			api.WORD,
 			api.DATA,
 			api.INT,
+			api.STRING_TYPE,
+			api.CHAR,
 			api.HI,
 			api.LO,
-			api.ADD,
-			api.SUB,
-			api.XOR,
+			api.PCREL_HI,
+			api.PCREL_LO,
+			// Also the "or" and "and" instructions
 			api.OR,
-			api.AND,
-			api.SLL,
-			api.SRL,
-			api.SRA,
-			api.SLT,
-			api.SLTU,
-			api.ADDI,
-			api.XORI,
-			api.ORI,
-			api.ANDI,
-			api.SLLI,
-			api.SRLI,
-			api.SRAI,
-			api.SLTI,
-			api.SLTIU,
-			api.LB,
-			api.LH,
-			api.LW,
-			api.LBU,
-			api.LHU,
-			api.SB,
-			api.SH,
-			api.SW,
-			api.BEQ,
-			api.BNE,
-			api.BLT,
-			api.BGE,
-			api.BLTU,
-			api.BGEU,
-			api.JAL,
-			api.JALR,
-			api.LUI,
-			api.AUIPC,
-			api.ECALL,
-			api.EBREAK,
-			api.LA,
-			api.NOP,
-			api.LI,
-			api.MV,
-			api.NOT,
-			api.NEG,
-			api.NEGW,
-			api.SEXT,
-			api.SEQZ,
-			api.SNEZ,
-			api.SLTZ,
-			api.SGTZ,
-			api.BEQZ,
-			api.BNEZ,
-			api.BLEZ,
-			api.BGEZ,
-			api.BLTZ,
-			api.BGTZ,
-			api.BGT,
-			api.BLE,
-			api.BGTU,
-			api.BLEU,
-			api.J,
-			api.RET,
-			api.CALL,
-			api.TAIL:
+			api.AND:
 			return
 		}
-	}
 
-	p.advance()
+		p.advance()
+	}
 }
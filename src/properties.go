@@ -1,13 +1,41 @@
 package src
 
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner/literals"
+)
+
 type configJSON struct {
 	BinaryName string
 	Generate   string // "Binary", "Ascii"
+	// Directories searched for imported modules after the
+	// config directory. Relative paths are relative to the config directory.
+	SearchPaths []string
+	// Shortens "call", "tail" and "la" where the target is in reach.
+	// Sections can override it with "relax" or "noRelax".
+	Relax bool
+	// Name of the symbol whose address is held in gp. gp relative
+	// relaxation is only done if it is given.
+	GlobalPointer string
+	// Constants visible to every module, for example
+	// {"BOARD": "hifive1", "DEBUG": true}
+	Defines map[string]interface{}
+	// The target, for example "rv32imac". Instructions of extensions
+	// it doesn't have are errors. Defaults to "rv32i".
+	ISA string
 }
 
 type Properties struct {
 	Config configJSON
 	Source []string
+
+	// The defines as literals
+	defines map[string]interface{}
+	// Lower case, "m" or "zicsr"
+	extensions map[string]bool
 }
 
 func (p *Properties) BinaryName() string {
@@ -17,3 +45,85 @@ func (p *Properties) BinaryName() string {
 func (p *Properties) Files() []string {
 	return p.Source
 }
+
+func (p *Properties) SearchPaths() []string {
+	return p.Config.SearchPaths
+}
+
+func (p *Properties) Relax() bool {
+	return p.Config.Relax
+}
+
+func (p *Properties) GlobalPointer() string {
+	return p.Config.GlobalPointer
+}
+
+func (p *Properties) Defines() map[string]interface{} {
+	return p.defines
+}
+
+// Converts the JSON define values into literals. Numbers without a
+// fraction become integers.
+func (p *Properties) convertDefines() error {
+	p.defines = map[string]interface{}{}
+
+	for name, value := range p.Config.Defines {
+		switch v := value.(type) {
+		case string:
+			p.defines[name] = literals.NewStringLiteral(v)
+		case bool:
+			p.defines[name] = literals.NewBooleanLiteral(v)
+		case float64:
+			if v == math.Trunc(v) {
+				p.defines[name] = literals.NewIntegerLiteralVal(int(v))
+			} else {
+				p.defines[name] = literals.NewNumberLiteralVal(v)
+			}
+		default:
+			return fmt.Errorf("define '%s' must be a string, number or boolean", name)
+		}
+	}
+
+	return nil
+}
+
+func (p *Properties) ISA() string {
+	if p.Config.ISA == "" {
+		return "rv32i"
+	}
+
+	return p.Config.ISA
+}
+
+func (p *Properties) HasExtension(name string) bool {
+	return p.extensions[strings.ToLower(name)]
+}
+
+// Splits the ISA into its extensions. Single letter extensions follow
+// the base, the others are separated by "_", for example
+// "rv32imac_zicsr". "g" stands for "imafd_zicsr_zifencei".
+func (p *Properties) convertISA() error {
+	isa := strings.ToLower(p.ISA())
+	p.extensions = map[string]bool{}
+
+	if !strings.HasPrefix(isa, "rv32i") && !strings.HasPrefix(isa, "rv32e") && !strings.HasPrefix(isa, "rv32g") {
+		return fmt.Errorf("ISA '%s' must start with 'rv32i', 'rv32e' or 'rv32g'", p.ISA())
+	}
+
+	parts := strings.Split(isa[len("rv32"):], "_")
+
+	for _, letter := range parts[0] {
+		if letter == 'g' {
+			parts = append(parts, "i", "m", "a", "f", "d", "zicsr", "zifencei")
+			continue
+		}
+
+		p.extensions[string(letter)] = true
+	}
+
+	for _, extension := range parts[1:] {
+		p.extensions[extension] = true
+	}
+
+	return nil
+}
@@ -15,6 +15,7 @@ const (
 	// defined here will be the default
 	FTYPE_NONE FunctionType = iota
 	FTYPE_FUNCTION
+	FTYPE_MACRO
 )
 
 type Resolver struct {
@@ -26,6 +27,10 @@ type Resolver struct {
 	// local scopes, we assume it must be global.
 	scopes *stack
 
+	// Parallels "scopes" but tracks which names are constants. The bottom
+	// node is the global scope.
+	constants *stack
+
 	// A gate-window to detect if we are in or out of a function
 	currentFunction FunctionType
 
@@ -37,6 +42,8 @@ func NewResolver(interpreter api.IInterpreter) api.IResolver {
 	o := new(Resolver)
 	o.interpreter = interpreter
 	o.scopes = newStack()
+	o.constants = newStack()
+	o.constants.push(node{})
 	o.currentFunction = FTYPE_NONE
 	o.inALoop = false
 	return o
@@ -80,10 +87,37 @@ func (r *Resolver) resolveExpression(expr api.IExpression) (obj interface{}, err
 
 func (r *Resolver) beginScope() {
 	r.scopes.push(node{})
+	r.constants.push(node{})
 }
 
 func (r *Resolver) endScope() {
 	r.scopes.pop()
+	r.constants.pop()
+}
+
+func (r *Resolver) declareConstant(name api.IToken) (err api.IRuntimeError) {
+	err = r.declare(name)
+	if err != nil {
+		return err
+	}
+
+	r.define(name)
+	r.constants.top()[name.Lexeme()] = true
+
+	return nil
+}
+
+// Returns true if the innermost declaration of the name is a constant.
+func (r *Resolver) isConstant(name api.IToken) bool {
+	scopesSize := r.scopes.count()
+
+	for i := scopesSize - 1; i >= 0; i-- {
+		if _, ok := r.scopes.get(i)[name.Lexeme()]; ok {
+			return r.constants.get(i + 1)[name.Lexeme()]
+		}
+	}
+
+	return r.constants.get(0)[name.Lexeme()]
 }
 
 // Make it an error to reference a variable in its initializer.
@@ -9,6 +9,10 @@ import (
 // IVisitorExpression interface
 // -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ --
 func (r *Resolver) VisitAssignExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	if r.isConstant(exprV.Name()) {
+		return nil, errors.NewRuntimeError(exprV.Name(), "Can't assign to constant '"+exprV.Name().Lexeme()+"'.")
+	}
+
 	// First, we resolve the expression for the assigned value in case it also containsreferences to other variables.
 	_, err = r.resolveExpression(exprV.Expression()) // i.e. exprV.Value()
 	if err != nil {
@@ -110,3 +114,28 @@ func (r *Resolver) VisitVariableExpression(exprV api.IExpression) (obj interface
 
 	return nil, nil
 }
+
+func (r *Resolver) VisitGetExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	// Properties are looked up dynamically so only the object is resolved.
+	return r.resolveExpression(exprV.Object())
+}
+
+func (r *Resolver) VisitLocalLabelExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	// Local labels are found by position when the operand is evaluated.
+	return nil, nil
+}
+
+func (r *Resolver) VisitRelocationExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	return r.resolveExpression(exprV.Expression())
+}
+
+func (r *Resolver) VisitMemoryExpression(exprV api.IExpression) (obj interface{}, err api.IRuntimeError) {
+	if exprV.Expression() != nil {
+		_, err = r.resolveExpression(exprV.Expression())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r.resolveExpression(exprV.Base())
+}
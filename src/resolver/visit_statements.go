@@ -9,7 +9,47 @@ import (
 // IVisitorStatement implementations
 // -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ -- ~~ --
 func (r *Resolver) VisitExpressionStatement(statement api.IStatement) (err api.IRuntimeError) {
-	r.resolveExpression(statement.Expression())
+	_, err = r.resolveExpression(statement.Expression())
+	return err
+}
+
+func (r *Resolver) VisitConstStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for c, name := range statement.Names() {
+		_, err = r.resolveExpression(statement.Initializers()[c])
+		if err != nil {
+			return err
+		}
+
+		err = r.declareConstant(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitEnumStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for _, initializer := range statement.Initializers() {
+		if initializer != nil {
+			_, err = r.resolveExpression(initializer)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.declareConstant(statement.Name())
+}
+
+func (r *Resolver) VisitImportStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for _, alias := range statement.Names() {
+		err = r.declareConstant(alias)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -83,6 +123,10 @@ func (r *Resolver) VisitReturnStatement(statement api.IStatement) (err api.IRunt
 		return errors.NewRuntimeError(statement.Keyword(), "Can't return from top-level code.")
 	}
 
+	if r.currentFunction == FTYPE_MACRO {
+		return errors.NewRuntimeError(statement.Keyword(), "Can't return from a macro.")
+	}
+
 	if statement.Value() != nil {
 		_, err = r.resolveExpression(statement.Value())
 		if err != nil {
@@ -116,3 +160,112 @@ func (r *Resolver) VisitWhileStatement(statement api.IStatement) (err api.IRunti
 
 	return nil
 }
+
+func (r *Resolver) VisitCodeSectionStatement(statement api.IStatement) (err api.IRuntimeError) {
+	if statement.Attributes().At() != nil {
+		_, err = r.resolveExpression(statement.Attributes().At())
+		if err != nil {
+			return err
+		}
+	}
+
+	r.beginScope()
+
+	err = r.resolveStatements(statement.Statements())
+	if err != nil {
+		return err
+	}
+
+	r.endScope()
+
+	return nil
+}
+
+func (r *Resolver) VisitInstructionStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for _, operand := range statement.Operands() {
+		_, err = r.resolveExpression(operand)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitLabelStatement(statement api.IStatement) (err api.IRuntimeError) {
+	// Labels are symbols of the section rather than variables, so there is
+	// nothing to declare in the scope.
+	return nil
+}
+
+func (r *Resolver) VisitUseStatement(statement api.IStatement) (err api.IRuntimeError) {
+	return nil
+}
+
+func (r *Resolver) VisitMacroStatement(statement api.IStatement) (err api.IRuntimeError) {
+	err = r.declare(statement.Name())
+	if err != nil {
+		return err
+	}
+	r.define(statement.Name())
+
+	return r.resolveFunction(statement, FTYPE_MACRO)
+}
+
+func (r *Resolver) VisitMacroInvocationStatement(statement api.IStatement) (err api.IRuntimeError) {
+	_, err = r.resolveExpression(statement.Expression())
+	if err != nil {
+		return err
+	}
+
+	for _, argument := range statement.Operands() {
+		_, err = r.resolveExpression(argument)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitStructStatement(statement api.IStatement) (err api.IRuntimeError) {
+	for _, field := range statement.Statements() {
+		if field.Expression() != nil {
+			_, err = r.resolveExpression(field.Expression())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.declareConstant(statement.Name())
+}
+
+func (r *Resolver) VisitDataSectionStatement(statement api.IStatement) (err api.IRuntimeError) {
+	if statement.Attributes().At() != nil {
+		_, err = r.resolveExpression(statement.Attributes().At())
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.resolveStatements(statement.Statements())
+}
+
+func (r *Resolver) VisitDataElementStatement(statement api.IStatement) (err api.IRuntimeError) {
+	if statement.Expression() != nil {
+		_, err = r.resolveExpression(statement.Expression())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, initializer := range statement.Initializers() {
+		_, err = r.resolveExpression(initializer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import "fmt"
+
+// CSR numbers by name, for the unprivileged counters and float CSRs
+// and the supervisor and machine CSRs. The numbered ones, such as
+// "pmpaddr12" or "mhpmcounter3", are added by init.
+var CSRs = map[string]int{
+	"fflags":   0x001,
+	"frm":      0x002,
+	"fcsr":     0x003,
+	"cycle":    0xc00,
+	"time":     0xc01,
+	"instret":  0xc02,
+	"cycleh":   0xc80,
+	"timeh":    0xc81,
+	"instreth": 0xc82,
+
+	"sstatus":    0x100,
+	"sie":        0x104,
+	"stvec":      0x105,
+	"scounteren": 0x106,
+	"senvcfg":    0x10a,
+	"sscratch":   0x140,
+	"sepc":       0x141,
+	"scause":     0x142,
+	"stval":      0x143,
+	"sip":        0x144,
+	"satp":       0x180,
+
+	"mvendorid":     0xf11,
+	"marchid":       0xf12,
+	"mimpid":        0xf13,
+	"mhartid":       0xf14,
+	"mconfigptr":    0xf15,
+	"mstatus":       0x300,
+	"misa":          0x301,
+	"medeleg":       0x302,
+	"mideleg":       0x303,
+	"mie":           0x304,
+	"mtvec":         0x305,
+	"mcounteren":    0x306,
+	"menvcfg":       0x30a,
+	"mstatush":      0x310,
+	"menvcfgh":      0x31a,
+	"mcountinhibit": 0x320,
+	"mscratch":      0x340,
+	"mepc":          0x341,
+	"mcause":        0x342,
+	"mtval":         0x343,
+	"mip":           0x344,
+	"mtinst":        0x34a,
+	"mtval2":        0x34b,
+	"mcycle":        0xb00,
+	"minstret":      0xb02,
+	"mcycleh":       0xb80,
+	"minstreth":     0xb82,
+}
+
+func init() {
+	for n := 3; n < 32; n++ {
+		CSRs[fmt.Sprintf("hpmcounter%d", n)] = 0xc00 + n
+		CSRs[fmt.Sprintf("hpmcounter%dh", n)] = 0xc80 + n
+		CSRs[fmt.Sprintf("mhpmcounter%d", n)] = 0xb00 + n
+		CSRs[fmt.Sprintf("mhpmcounter%dh", n)] = 0xb80 + n
+		CSRs[fmt.Sprintf("mhpmevent%d", n)] = 0x320 + n
+	}
+
+	for n := 0; n < 16; n++ {
+		CSRs[fmt.Sprintf("pmpcfg%d", n)] = 0x3a0 + n
+	}
+
+	for n := 0; n < 64; n++ {
+		CSRs[fmt.Sprintf("pmpaddr%d", n)] = 0x3b0 + n
+	}
+}
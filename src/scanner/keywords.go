@@ -1,39 +1,58 @@
 package scanner
 
-import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+import (
+	"strings"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
 
 var Keywords = map[string]api.TokenType{
-	"const":    api.CONST,
-	"import":   api.IMPORT,
-	"print":    api.PRINT,
-	"var":      api.VAR,
-	"nil":      api.NIL,
-	"true":     api.TRUE,
-	"false":    api.FALSE,
-	"and":      api.AND,
-	"or":       api.OR,
-	"if":       api.IF,
-	"else":     api.ELSE,
-	"while":    api.WHILE,
-	"for":      api.FOR,
-	"break":    api.BREAK,
-	"continue": api.CONTINUE,
-	"fun":      api.FUN,
-	"return":   api.RETURN,
-	"code":     api.CODE,
-	"alignTo":  api.ALIGN_TO,
-	"global":   api.GLOBAL,
-	"at":       api.AT,
-	"as":       api.AS,
-	"use":      api.USE,
-	"readOnly": api.READ_ONLY,
-	"byte":     api.BYTE,
-	"half":     api.HALF,
-	"word":     api.WORD,
-	"data":     api.DATA,
-	"int":      api.INT,
-	"hi":       api.HI,
-	"lo":       api.LO,
+	"const":      api.CONST,
+	"import":     api.IMPORT,
+	"print":      api.PRINT,
+	"var":        api.VAR,
+	"nil":        api.NIL,
+	"true":       api.TRUE,
+	"false":      api.FALSE,
+	"and":        api.AND,
+	"or":         api.OR,
+	"if":         api.IF,
+	"else":       api.ELSE,
+	"while":      api.WHILE,
+	"for":        api.FOR,
+	"break":      api.BREAK,
+	"continue":   api.CONTINUE,
+	"fun":        api.FUN,
+	"return":     api.RETURN,
+	"code":       api.CODE,
+	"alignTo":    api.ALIGN_TO,
+	"global":     api.GLOBAL,
+	"at":         api.AT,
+	"as":         api.AS,
+	"use":        api.USE,
+	"macro":      api.MACRO,
+	"struct":     api.STRUCT,
+	"enum":       api.ENUM,
+	"csr":        api.CSR,
+	"readOnly":   api.READ_ONLY,
+	"readWrite":  api.READ_WRITE,
+	"relax":      api.RELAX,
+	"noRelax":    api.NO_RELAX,
+	"noCompress": api.NO_COMPRESS,
+	"bytes":      api.BYTES,
+	"byte":       api.BYTE,
+	"half":       api.HALF,
+	"word":       api.WORD,
+	"data":       api.DATA,
+	"int":        api.INT,
+	"string":     api.STRING_TYPE,
+	"char":       api.CHAR,
+	"float":      api.FLOAT,
+	"double":     api.DOUBLE,
+	"hi":         api.HI,
+	"lo":         api.LO,
+	"pcrel_hi":   api.PCREL_HI,
+	"pcrel_lo":   api.PCREL_LO,
 
 	// Instructions
 	"add": api.ADD,
@@ -75,31 +94,197 @@ var Keywords = map[string]api.TokenType{
 	"auipc":  api.AUIPC,
 	"ecall":  api.ECALL,
 	"ebreak": api.EBREAK,
+	// RV32M
+	"mul":    api.MUL,
+	"mulh":   api.MULH,
+	"mulhsu": api.MULHSU,
+	"mulhu":  api.MULHU,
+	"div":    api.DIV,
+	"divu":   api.DIVU,
+	"rem":    api.REM,
+	"remu":   api.REMU,
+	// RV32A
+	"lr.w":      api.LR_W,
+	"sc.w":      api.SC_W,
+	"amoswap.w": api.AMOSWAP_W,
+	"amoadd.w":  api.AMOADD_W,
+	"amoxor.w":  api.AMOXOR_W,
+	"amoand.w":  api.AMOAND_W,
+	"amoor.w":   api.AMOOR_W,
+	"amomin.w":  api.AMOMIN_W,
+	"amomax.w":  api.AMOMAX_W,
+	"amominu.w": api.AMOMINU_W,
+	"amomaxu.w": api.AMOMAXU_W,
+	// RV32F and RV32D
+	"flw":       api.FLW,
+	"fsw":       api.FSW,
+	"fld":       api.FLD,
+	"fsd":       api.FSD,
+	"fadd.s":    api.FADD_S,
+	"fsub.s":    api.FSUB_S,
+	"fmul.s":    api.FMUL_S,
+	"fdiv.s":    api.FDIV_S,
+	"fsqrt.s":   api.FSQRT_S,
+	"fsgnj.s":   api.FSGNJ_S,
+	"fsgnjn.s":  api.FSGNJN_S,
+	"fsgnjx.s":  api.FSGNJX_S,
+	"fmin.s":    api.FMIN_S,
+	"fmax.s":    api.FMAX_S,
+	"fmadd.s":   api.FMADD_S,
+	"fmsub.s":   api.FMSUB_S,
+	"fnmsub.s":  api.FNMSUB_S,
+	"fnmadd.s":  api.FNMADD_S,
+	"fcvt.w.s":  api.FCVT_W_S,
+	"fcvt.wu.s": api.FCVT_WU_S,
+	"fcvt.s.w":  api.FCVT_S_W,
+	"fcvt.s.wu": api.FCVT_S_WU,
+	"feq.s":     api.FEQ_S,
+	"flt.s":     api.FLT_S,
+	"fle.s":     api.FLE_S,
+	"fclass.s":  api.FCLASS_S,
+	"fmv.x.w":   api.FMV_X_W,
+	"fmv.w.x":   api.FMV_W_X,
+	"fadd.d":    api.FADD_D,
+	"fsub.d":    api.FSUB_D,
+	"fmul.d":    api.FMUL_D,
+	"fdiv.d":    api.FDIV_D,
+	"fsqrt.d":   api.FSQRT_D,
+	"fsgnj.d":   api.FSGNJ_D,
+	"fsgnjn.d":  api.FSGNJN_D,
+	"fsgnjx.d":  api.FSGNJX_D,
+	"fmin.d":    api.FMIN_D,
+	"fmax.d":    api.FMAX_D,
+	"fmadd.d":   api.FMADD_D,
+	"fmsub.d":   api.FMSUB_D,
+	"fnmsub.d":  api.FNMSUB_D,
+	"fnmadd.d":  api.FNMADD_D,
+	"fcvt.w.d":  api.FCVT_W_D,
+	"fcvt.wu.d": api.FCVT_WU_D,
+	"fcvt.d.w":  api.FCVT_D_W,
+	"fcvt.d.wu": api.FCVT_D_WU,
+	"feq.d":     api.FEQ_D,
+	"flt.d":     api.FLT_D,
+	"fle.d":     api.FLE_D,
+	"fclass.d":  api.FCLASS_D,
+	"fcvt.s.d":  api.FCVT_S_D,
+	"fcvt.d.s":  api.FCVT_D_S,
+	// Zicsr
+	"csrrw":  api.CSRRW,
+	"csrrs":  api.CSRRS,
+	"csrrc":  api.CSRRC,
+	"csrrwi": api.CSRRWI,
+	"csrrsi": api.CSRRSI,
+	"csrrci": api.CSRRCI,
+	// RVC
+	"c.nop":      api.C_NOP,
+	"c.addi":     api.C_ADDI,
+	"c.addi16sp": api.C_ADDI16SP,
+	"c.addi4spn": api.C_ADDI4SPN,
+	"c.li":       api.C_LI,
+	"c.lui":      api.C_LUI,
+	"c.mv":       api.C_MV,
+	"c.add":      api.C_ADD,
+	"c.sub":      api.C_SUB,
+	"c.xor":      api.C_XOR,
+	"c.or":       api.C_OR,
+	"c.and":      api.C_AND,
+	"c.andi":     api.C_ANDI,
+	"c.slli":     api.C_SLLI,
+	"c.srli":     api.C_SRLI,
+	"c.srai":     api.C_SRAI,
+	"c.lw":       api.C_LW,
+	"c.sw":       api.C_SW,
+	"c.lwsp":     api.C_LWSP,
+	"c.swsp":     api.C_SWSP,
+	"c.flw":      api.C_FLW,
+	"c.fsw":      api.C_FSW,
+	"c.flwsp":    api.C_FLWSP,
+	"c.fswsp":    api.C_FSWSP,
+	"c.fld":      api.C_FLD,
+	"c.fsd":      api.C_FSD,
+	"c.fldsp":    api.C_FLDSP,
+	"c.fsdsp":    api.C_FSDSP,
+	"c.j":        api.C_J,
+	"c.jal":      api.C_JAL,
+	"c.jr":       api.C_JR,
+	"c.jalr":     api.C_JALR,
+	"c.beqz":     api.C_BEQZ,
+	"c.bnez":     api.C_BNEZ,
+	"c.ebreak":   api.C_EBREAK,
 	// Pseudo instructions
-	"la":   api.LA,
-	"nop":  api.NOP,
-	"li":   api.LI,
-	"mv":   api.MV,
-	"not":  api.NOT,
-	"neg":  api.NEG,
-	"negw": api.NEGW,
-	"sext": api.SEXT,
-	"seqz": api.SEQZ,
-	"snez": api.SNEZ,
-	"sltz": api.SLTZ,
-	"sgtz": api.SGTZ,
-	"beqz": api.BEQZ,
-	"bnez": api.BNEZ,
-	"blez": api.BLEZ,
-	"bgez": api.BGEZ,
-	"bltz": api.BLTZ,
-	"bgtz": api.BGTZ,
-	"bgt":  api.BGT,
-	"ble":  api.BLE,
-	"bgtu": api.BGTU,
-	"bleu": api.BLEU,
-	"j":    api.J,
-	"ret":  api.RET,
-	"call": api.CALL,
-	"tail": api.TAIL,
+	"la":     api.LA,
+	"nop":    api.NOP,
+	"li":     api.LI,
+	"mv":     api.MV,
+	"not":    api.NOT,
+	"neg":    api.NEG,
+	"negw":   api.NEGW,
+	"sext.w": api.SEXT,
+	"seqz":   api.SEQZ,
+	"snez":   api.SNEZ,
+	"sltz":   api.SLTZ,
+	"sgtz":   api.SGTZ,
+	"beqz":   api.BEQZ,
+	"bnez":   api.BNEZ,
+	"blez":   api.BLEZ,
+	"bgez":   api.BGEZ,
+	"bltz":   api.BLTZ,
+	"bgtz":   api.BGTZ,
+	"bgt":    api.BGT,
+	"ble":    api.BLE,
+	"bgtu":   api.BGTU,
+	"bleu":   api.BLEU,
+	"j":      api.J,
+	"ret":    api.RET,
+	"call":   api.CALL,
+	"tail":   api.TAIL,
+	"fmv.s":  api.FMV_S,
+	"fneg.s": api.FNEG_S,
+	"fabs.s": api.FABS_S,
+	"fmv.d":  api.FMV_D,
+	"fneg.d": api.FNEG_D,
+	"fabs.d": api.FABS_D,
+	"csrr":   api.CSRR,
+	"csrw":   api.CSRW,
+	"csrs":   api.CSRS,
+	"csrc":   api.CSRC,
+	"csrwi":  api.CSRWI,
+	"csrsi":  api.CSRSI,
+	"csrci":  api.CSRCI,
+}
+
+// Memory ordering suffixes of the atomic instructions
+var orderings = []string{".aqrl", ".aq", ".rl"}
+
+// The keyword's token type, UNDEFINED if it isn't one. An atomic
+// instruction may carry an ordering suffix.
+func Mnemonic(text string) api.TokenType {
+	if ttype, ok := Keywords[text]; ok {
+		return ttype
+	}
+
+	for _, ordering := range orderings {
+		if strings.HasSuffix(text, ordering) {
+			ttype := Keywords[strings.TrimSuffix(text, ordering)]
+			if ttype.IsAtomic() {
+				return ttype
+			}
+		}
+	}
+
+	return api.UNDEFINED
+}
+
+// The aq and rl bits of an atomic instruction's mnemonic, aq first
+func Ordering(mnemonic string) uint32 {
+	switch {
+	case strings.HasSuffix(mnemonic, ".aqrl"):
+		return 0x3
+	case strings.HasSuffix(mnemonic, ".aq"):
+		return 0x2
+	case strings.HasSuffix(mnemonic, ".rl"):
+		return 0x1
+	}
+
+	return 0
 }
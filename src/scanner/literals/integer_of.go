@@ -0,0 +1,26 @@
+package literals
+
+import (
+	"strconv"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// IntegerOf converts any of the integral literals (integer, hex,
+// binary and char) into an int.
+func IntegerOf(obj interface{}) (value int, ok bool) {
+	switch v := obj.(type) {
+	case api.IIntegerLiteral:
+		return v.IntValue(), true
+	case api.IHexNumberLiteral:
+		pv, err := strconv.ParseUint(v.HexValue(), 16, 64)
+		return int(pv), err == nil
+	case api.IBinaryNumberLiteral:
+		pv, err := strconv.ParseUint(v.BinValue(), 2, 64)
+		return int(pv), err == nil
+	case api.ICharLiteral:
+		return int(v.CharValue()), true
+	}
+
+	return 0, false
+}
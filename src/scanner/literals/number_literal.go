@@ -13,7 +13,7 @@ type NumberLiteral struct {
 
 func NewNumberLiteral(value string) api.INumberLiteral {
 	s := new(NumberLiteral)
-	s.value, _ = strconv.ParseFloat(value, 32)
+	s.value, _ = strconv.ParseFloat(value, 64)
 	return s
 }
 
@@ -0,0 +1,37 @@
+package literals
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+type RegisterLiteral struct {
+	name  string
+	value int
+	// One of f0-f31 rather than x0-x31
+	float bool
+}
+
+func NewRegisterLiteral(name string, value int) api.IRegisterLiteral {
+	s := new(RegisterLiteral)
+	s.name = name
+	s.value = value
+	return s
+}
+
+func NewFloatRegisterLiteral(name string, value int) api.IRegisterLiteral {
+	s := new(RegisterLiteral)
+	s.name = name
+	s.value = value
+	s.float = true
+	return s
+}
+
+func (r RegisterLiteral) String() string {
+	return r.name
+}
+
+func (r *RegisterLiteral) RegisterValue() int {
+	return r.value
+}
+
+func (r *RegisterLiteral) IsFloat() bool {
+	return r.float
+}
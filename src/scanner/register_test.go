@@ -0,0 +1,94 @@
+package scanner_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+	"github.com/wdevore/RISCV-Meta-Assembler/src/scanner"
+)
+
+// Scans text and returns its tokens without the EOF
+func scan(t *testing.T, text string) []api.IToken {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "main.S")
+	err := os.WriteFile(path, []byte(text), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assembler, _ := src.NewAssembler()
+	tokens, err := scanner.NewScanner(assembler).Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens[:len(tokens)-1]
+}
+
+func TestRegisters(t *testing.T) {
+	tests := []struct {
+		name   string
+		number int
+		float  bool
+	}{
+		{"zero", 0, false},
+		{"ra", 1, false},
+		{"sp", 2, false},
+		{"s0", 8, false},
+		{"fp", 8, false},
+		{"a0", 10, false},
+		{"s11", 27, false},
+		{"t6", 31, false},
+		{"x0", 0, false},
+		{"x17", 17, false},
+		{"x31", 31, false},
+		{"f0", 0, true},
+		{"f31", 31, true},
+		{"ft0", 0, true},
+		{"fs1", 9, true},
+		{"fa0", 10, true},
+		{"fa7", 17, true},
+		{"fs2", 18, true},
+		{"fs11", 27, true},
+		{"ft8", 28, true},
+		{"ft11", 31, true},
+	}
+
+	names := []string{}
+	for _, test := range tests {
+		names = append(names, test.name)
+	}
+
+	tokens := scan(t, strings.Join(names, " "))
+	if len(tokens) != len(tests) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(tests))
+	}
+
+	for index, test := range tests {
+		token := tokens[index]
+		if token.Type() != api.REGISTER {
+			t.Errorf("'%s' scanned as %s", test.name, token.Type())
+			continue
+		}
+
+		register := token.Literal().(api.IRegisterLiteral)
+		if register.RegisterValue() != test.number || register.IsFloat() != test.float {
+			t.Errorf("'%s' is register %d, float %v, want %d, float %v",
+				test.name, register.RegisterValue(), register.IsFloat(), test.number, test.float)
+		}
+	}
+}
+
+// Names that only look like registers are identifiers
+func TestNotRegisters(t *testing.T) {
+	for _, token := range scan(t, "x32 xa a8 f32 fa8 ft12 sp1 zeros") {
+		if token.Type() != api.IDENTIFIER {
+			t.Errorf("'%s' scanned as %s", token.Lexeme(), token.Type())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import "fmt"
+
+// Integer registers by ABI name. The "xN" names are added by init.
+var Registers = map[string]int{
+	"zero": 0,
+	"ra":   1,
+	"sp":   2,
+	"gp":   3,
+	"tp":   4,
+	"t0":   5,
+	"t1":   6,
+	"t2":   7,
+	"s0":   8,
+	"fp":   8,
+	"s1":   9,
+	"a0":   10,
+	"a1":   11,
+	"a2":   12,
+	"a3":   13,
+	"a4":   14,
+	"a5":   15,
+	"a6":   16,
+	"a7":   17,
+	"s2":   18,
+	"s3":   19,
+	"s4":   20,
+	"s5":   21,
+	"s6":   22,
+	"s7":   23,
+	"s8":   24,
+	"s9":   25,
+	"s10":  26,
+	"s11":  27,
+	"t3":   28,
+	"t4":   29,
+	"t5":   30,
+	"t6":   31,
+}
+
+// Float registers by ABI name. The "fN" names are added by init.
+var FloatRegisters = map[string]int{}
+
+func init() {
+	for n := 0; n < 32; n++ {
+		Registers[fmt.Sprintf("x%d", n)] = n
+		FloatRegisters[fmt.Sprintf("f%d", n)] = n
+	}
+
+	for n := 0; n < 8; n++ {
+		FloatRegisters[fmt.Sprintf("ft%d", n)] = n
+		FloatRegisters[fmt.Sprintf("fa%d", n)] = 10 + n
+	}
+
+	for n := 0; n < 12; n++ {
+		if n < 2 {
+			FloatRegisters[fmt.Sprintf("fs%d", n)] = 8 + n
+		} else {
+			FloatRegisters[fmt.Sprintf("fs%d", n)] = 16 + n
+		}
+	}
+
+	for n := 8; n < 12; n++ {
+		FloatRegisters[fmt.Sprintf("ft%d", n)] = 20 + n
+	}
+}
@@ -31,13 +31,20 @@ func NewScanner(assembler api.IAssembler) *Scanner {
 func (s *Scanner) Scan(source string) (tokens []api.IToken, err error) {
 	s.source = source
 
-	dataPath, err := filepath.Abs(s.assembler.ConfigRelPath())
+	// Relative sources are relative to the config directory.
+	path := source
 
-	if err != nil {
-		return nil, err
+	if !filepath.IsAbs(source) {
+		dataPath, err := filepath.Abs(s.assembler.ConfigRelPath())
+
+		if err != nil {
+			return nil, err
+		}
+
+		path = dataPath + "/" + source
 	}
 
-	bytes, err := ioutil.ReadFile(dataPath + "/" + source)
+	bytes, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +90,8 @@ func (s *Scanner) scanToken() {
 		s.addTokenNullLiteral(api.COMMA)
 	case ";":
 		s.addTokenNullLiteral(api.SEMICOLON)
+	case ":":
+		s.addTokenNullLiteral(api.COLON)
 	case ".":
 		s.addTokenNullLiteral(api.DOT)
 	case "-":
@@ -91,6 +100,8 @@ func (s *Scanner) scanToken() {
 		s.addTokenNullLiteral(api.PLUS)
 	case "*":
 		s.addTokenNullLiteral(api.STAR)
+	case "|":
+		s.addTokenNullLiteral(api.PIPE)
 	case "%":
 		s.addTokenNullLiteral(api.PERCENT)
 	case "!":
@@ -304,6 +315,15 @@ func (s *Scanner) number() {
 		return
 	}
 
+	// A numeric local label reference, for example "1b" (backward)
+	// or "1f" (forward).
+	if (s.peek() == "b" || s.peek() == "f") && !s.isAlphaNumeric(s.peekNext()) {
+		value := s.source[s.start:s.current]
+		s.advance()
+		s.addToken(api.LOCAL_LABEL, literals.NewIntegerLiteral(value))
+		return
+	}
+
 	value := s.source[s.start:s.current]
 	s.addToken(api.NUMBER, literals.NewIntegerLiteral(value))
 }
@@ -314,7 +334,22 @@ func (s *Scanner) identifier() {
 	}
 
 	text := s.source[s.start:s.current]
-	ttype := Keywords[text]
+
+	if s.peek() == "." {
+		text = s.dottedMnemonic()
+	}
+
+	if number, isRegister := Registers[text]; isRegister {
+		s.addToken(api.REGISTER, literals.NewRegisterLiteral(text, number))
+		return
+	}
+
+	if number, isRegister := FloatRegisters[text]; isRegister {
+		s.addToken(api.REGISTER, literals.NewFloatRegisterLiteral(text, number))
+		return
+	}
+
+	ttype := Mnemonic(text)
 	if ttype == api.UNDEFINED {
 		ttype = api.IDENTIFIER
 	}
@@ -322,6 +357,31 @@ func (s *Scanner) identifier() {
 	s.addTokenNullLiteral(ttype)
 }
 
+// Extends the identifier just scanned to the longest dotted mnemonic
+// it starts, for example "lr.w" or "amoswap.w.aqrl". Anything else,
+// such as "Point.y", is left as it was.
+func (s *Scanner) dottedMnemonic() string {
+	ends := []int{}
+
+	end := s.current
+	for end+1 < len(s.source) && s.source[end] == '.' && s.isAlpha(string(s.source[end+1])) {
+		end++
+		for end < len(s.source) && s.isAlphaNumeric(string(s.source[end])) {
+			end++
+		}
+		ends = append(ends, end)
+	}
+
+	for e := len(ends) - 1; e >= 0; e-- {
+		if Mnemonic(s.source[s.start:ends[e]]).IsInstruction() {
+			s.current = ends[e]
+			break
+		}
+	}
+
+	return s.source[s.start:s.current]
+}
+
 func (s *Scanner) peekNext() string {
 	if s.current+1 >= len(s.source) {
 		return "" // "\0"
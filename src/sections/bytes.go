@@ -0,0 +1,37 @@
+package sections
+
+// Align rounds offset up to the next multiple of alignment.
+func Align(offset, alignment int) int {
+	if alignment <= 1 {
+		return offset
+	}
+
+	return (offset + alignment - 1) / alignment * alignment
+}
+
+// LittleEndian returns the lowest "size" bytes of value, least
+// significant byte first.
+func LittleEndian(value int, size int) []byte {
+	bytes := make([]byte, size)
+
+	for i := 0; i < size; i++ {
+		bytes[i] = byte(value >> (8 * i))
+	}
+
+	return bytes
+}
+
+// Fits returns true if value can be stored in "size" bytes either
+// as a signed or an unsigned number.
+func Fits(value int, size int) bool {
+	if size >= 8 {
+		return true
+	}
+
+	// In int64 so a word's range doesn't overflow a 32-bit int
+	bits := uint(8 * size)
+	min := -(int64(1) << (bits - 1))
+	max := int64(1)<<bits - 1
+
+	return int64(value) >= min && int64(value) <= max
+}
@@ -0,0 +1,72 @@
+package sections_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/sections"
+)
+
+func TestFits(t *testing.T) {
+	tests := []struct {
+		value int
+		size  int
+		fits  bool
+	}{
+		{255, 1, true},
+		{-128, 1, true},
+		{256, 1, false},
+		{-129, 1, false},
+		{0xffff, 2, true},
+		{-0x8000, 2, true},
+		{0x10000, 2, false},
+		{4, 4, true},
+		{0x7fffffff, 4, true},
+		{-0x80000000, 4, true},
+		{-1, 8, true},
+	}
+
+	for _, test := range tests {
+		if fits := sections.Fits(test.value, test.size); fits != test.fits {
+			t.Errorf("Fits(%#x, %d) = %v, want %v", test.value, test.size, fits, test.fits)
+		}
+	}
+}
+
+func TestLittleEndian(t *testing.T) {
+	tests := []struct {
+		value int
+		size  int
+		want  []byte
+	}{
+		{0x1234, 2, []byte{0x34, 0x12}},
+		{0x12345678, 4, []byte{0x78, 0x56, 0x34, 0x12}},
+		{-2, 4, []byte{0xfe, 0xff, 0xff, 0xff}},
+		{-1, 8, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{0x1ff, 1, []byte{0xff}},
+	}
+
+	for _, test := range tests {
+		if got := sections.LittleEndian(test.value, test.size); !bytes.Equal(got, test.want) {
+			t.Errorf("LittleEndian(%#x, %d) = % x, want % x", test.value, test.size, got, test.want)
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	tests := []struct{ offset, alignment, want int }{
+		{0, 4, 0},
+		{1, 4, 4},
+		{4, 4, 4},
+		{5, 2, 6},
+		{7, 1, 7},
+		{7, 0, 7},
+		{17, 16, 32},
+	}
+
+	for _, test := range tests {
+		if got := sections.Align(test.offset, test.alignment); got != test.want {
+			t.Errorf("Align(%d, %d) = %d, want %d", test.offset, test.alignment, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package sections
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+type Item struct {
+	kind        api.ItemKind
+	statement   api.IStatement
+	environment api.IEnvironment
+	interpreter api.IInterpreter
+	invocations []api.IToken
+
+	offset int
+	size   int
+	bytes  []byte
+
+	expansion    []string
+	relaxed      bool
+	long         bool
+	uncompressed bool
+}
+
+func NewItem(kind api.ItemKind, statement api.IStatement, environment api.IEnvironment, interpreter api.IInterpreter) api.ISectionItem {
+	o := new(Item)
+	o.kind = kind
+	o.statement = statement
+	o.environment = environment
+	o.interpreter = interpreter
+	return o
+}
+
+func (i *Item) Kind() api.ItemKind {
+	return i.kind
+}
+
+func (i *Item) Statement() api.IStatement {
+	return i.statement
+}
+
+func (i *Item) Environment() api.IEnvironment {
+	return i.environment
+}
+
+func (i *Item) Interpreter() api.IInterpreter {
+	return i.interpreter
+}
+
+func (i *Item) Invocations() []api.IToken {
+	return i.invocations
+}
+
+func (i *Item) SetInvocations(invocations []api.IToken) {
+	i.invocations = invocations
+}
+
+func (i *Item) Offset() int {
+	return i.offset
+}
+
+func (i *Item) SetOffset(offset int) {
+	i.offset = offset
+}
+
+func (i *Item) Size() int {
+	return i.size
+}
+
+func (i *Item) SetSize(size int) {
+	i.size = size
+}
+
+func (i *Item) Bytes() []byte {
+	return i.bytes
+}
+
+func (i *Item) SetBytes(bytes []byte) {
+	i.bytes = bytes
+	i.size = len(bytes)
+}
+
+func (i *Item) Expansion() []string {
+	return i.expansion
+}
+
+func (i *Item) SetExpansion(expansion []string) {
+	i.expansion = expansion
+}
+
+func (i *Item) Relaxed() bool {
+	return i.relaxed
+}
+
+func (i *Item) SetRelaxed(relaxed bool) {
+	i.relaxed = relaxed
+}
+
+func (i *Item) LongBranch() bool {
+	return i.long
+}
+
+func (i *Item) SetLongBranch(long bool) {
+	i.long = long
+}
+
+func (i *Item) Uncompressed() bool {
+	return i.uncompressed
+}
+
+func (i *Item) SetUncompressed(uncompressed bool) {
+	i.uncompressed = uncompressed
+}
+
+func (i Item) String() string {
+	return i.kind.String()
+}
@@ -0,0 +1,111 @@
+package sections
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+type Section struct {
+	name       api.IToken
+	kind       api.SectionKind
+	attributes api.IAttributes
+	items      []api.ISectionItem
+
+	origin    int
+	hasOrigin bool
+	address   int
+
+	interpreter api.IInterpreter
+}
+
+func NewSection(name api.IToken, kind api.SectionKind, attributes api.IAttributes, interpreter api.IInterpreter) api.ISection {
+	o := new(Section)
+	o.interpreter = interpreter
+	o.name = name
+	o.kind = kind
+	o.attributes = attributes
+	o.items = []api.ISectionItem{}
+	return o
+}
+
+func (s *Section) Name() api.IToken {
+	return s.name
+}
+
+func (s *Section) Kind() api.SectionKind {
+	return s.kind
+}
+
+func (s *Section) Attributes() api.IAttributes {
+	return s.attributes
+}
+
+func (s *Section) Origin() (address int, ok bool) {
+	return s.origin, s.hasOrigin
+}
+
+func (s *Section) SetOrigin(address int) {
+	s.origin = address
+	s.hasOrigin = true
+	s.address = address
+}
+
+func (s *Section) Interpreter() api.IInterpreter {
+	return s.interpreter
+}
+
+func (s *Section) Address() int {
+	return s.address
+}
+
+func (s *Section) SetAddress(address int) {
+	s.address = address
+}
+
+func (s *Section) Items() []api.ISectionItem {
+	return s.items
+}
+
+func (s *Section) Append(item api.ISectionItem) {
+	s.items = append(s.items, item)
+}
+
+func (s *Section) Lookup(name string) api.ISectionItem {
+	for _, item := range s.items {
+		if item.Kind() == api.ITEM_INSTRUCTION {
+			continue
+		}
+
+		label := item.Statement().Name()
+		if label != nil && label.Lexeme() == name {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func (s *Section) Size() int {
+	size := 0
+
+	for _, item := range s.items {
+		end := item.Offset() + item.Size()
+		if end > size {
+			size = end
+		}
+	}
+
+	return size
+}
+
+func (s *Section) Image() []byte {
+	// Gaps left by alignment padding are zero filled.
+	image := make([]byte, s.Size())
+
+	for _, item := range s.items {
+		copy(image[item.Offset():], item.Bytes())
+	}
+
+	return image
+}
+
+func (s Section) String() string {
+	return s.kind.String() + " section " + s.name.Lexeme()
+}
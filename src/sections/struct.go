@@ -0,0 +1,83 @@
+package sections
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+// Struct lays its fields out in order, each at its natural alignment.
+type Struct struct {
+	name      api.IToken
+	fields    []api.IStructField
+	end       int
+	alignment int
+}
+
+func NewStruct(name api.IToken) *Struct {
+	o := new(Struct)
+	o.name = name
+	o.alignment = 1
+	return o
+}
+
+// Places a field after the previous one, padded to its alignment.
+func (s *Struct) AddField(name api.IToken, size, alignment int, nested api.IStruct) {
+	offset := Align(s.end, alignment)
+
+	s.fields = append(s.fields, &StructField{name, offset, size, nested})
+	s.end = offset + size
+
+	if alignment > s.alignment {
+		s.alignment = alignment
+	}
+}
+
+func (s *Struct) Name() api.IToken {
+	return s.name
+}
+
+func (s *Struct) Size() int {
+	return Align(s.end, s.alignment)
+}
+
+func (s *Struct) Alignment() int {
+	return s.alignment
+}
+
+func (s *Struct) Fields() []api.IStructField {
+	return s.fields
+}
+
+func (s *Struct) Field(name string) (field api.IStructField, ok bool) {
+	for _, field := range s.fields {
+		if field.Name().Lexeme() == name {
+			return field, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s Struct) String() string {
+	return "<struct " + s.name.Lexeme() + ">"
+}
+
+type StructField struct {
+	name   api.IToken
+	offset int
+	size   int
+	nested api.IStruct
+}
+
+func (f *StructField) Name() api.IToken {
+	return f.name
+}
+
+func (f *StructField) Offset() int {
+	return f.offset
+}
+
+func (f *StructField) Size() int {
+	return f.size
+}
+
+func (f *StructField) Struct() api.IStruct {
+	return f.nested
+}
@@ -0,0 +1,50 @@
+package sections
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+type Symbol struct {
+	name    api.IToken
+	section api.ISection
+	item    api.ISectionItem
+	binding api.SymbolBinding
+}
+
+func NewSymbol(name api.IToken, section api.ISection, item api.ISectionItem, binding api.SymbolBinding) api.ISymbol {
+	o := new(Symbol)
+	o.name = name
+	o.section = section
+	o.item = item
+	o.binding = binding
+	return o
+}
+
+func (s *Symbol) Name() api.IToken {
+	return s.name
+}
+
+func (s *Symbol) Section() api.ISection {
+	return s.section
+}
+
+func (s *Symbol) Item() api.ISectionItem {
+	return s.item
+}
+
+func (s *Symbol) Binding() api.SymbolBinding {
+	return s.binding
+}
+
+func (s *Symbol) Offset() int {
+	if s.item == nil {
+		return 0
+	}
+	return s.item.Offset()
+}
+
+func (s *Symbol) Address() int {
+	return s.section.Address() + s.Offset()
+}
+
+func (s Symbol) String() string {
+	return "<symbol " + s.name.Lexeme() + ">"
+}
@@ -0,0 +1,39 @@
+package sections
+
+import "github.com/wdevore/RISCV-Meta-Assembler/src/api"
+
+type SymbolTable struct {
+	symbols []api.ISymbol
+}
+
+func NewSymbolTable() api.ISymbolTable {
+	o := new(SymbolTable)
+	o.symbols = []api.ISymbol{}
+	return o
+}
+
+func (t *SymbolTable) Add(symbol api.ISymbol) {
+	t.symbols = append(t.symbols, symbol)
+}
+
+func (t *SymbolTable) Symbols() []api.ISymbol {
+	return t.symbols
+}
+
+func (t *SymbolTable) Lookup(name string) (symbol api.ISymbol, ok bool) {
+	for _, s := range t.symbols {
+		if s.Name().Lexeme() != name {
+			continue
+		}
+
+		if s.Binding() == api.BINDING_GLOBAL {
+			return s, true
+		}
+
+		if symbol == nil {
+			symbol = s
+		}
+	}
+
+	return symbol, symbol != nil
+}
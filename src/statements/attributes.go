@@ -0,0 +1,79 @@
+package statements
+
+import (
+	"fmt"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// ---------------------------------------------------
+// Section attributes
+// ---------------------------------------------------
+type Attributes struct {
+	alignment int
+	global    bool
+	at        api.IExpression
+	access    api.AccessType
+	relax     api.RelaxType
+	compress  bool
+}
+
+func NewAttributes() *Attributes {
+	o := new(Attributes)
+	o.access = api.ACCESS_DEFAULT
+	o.relax = api.RELAX_DEFAULT
+	o.compress = true
+	return o
+}
+
+func (a *Attributes) Alignment() int {
+	return a.alignment
+}
+
+func (a *Attributes) SetAlignment(alignment int) {
+	a.alignment = alignment
+}
+
+func (a *Attributes) Global() bool {
+	return a.global
+}
+
+func (a *Attributes) SetGlobal(global bool) {
+	a.global = global
+}
+
+func (a *Attributes) At() api.IExpression {
+	return a.at
+}
+
+func (a *Attributes) SetAt(at api.IExpression) {
+	a.at = at
+}
+
+func (a *Attributes) Access() api.AccessType {
+	return a.access
+}
+
+func (a *Attributes) SetAccess(access api.AccessType) {
+	a.access = access
+}
+
+func (a *Attributes) Relax() api.RelaxType {
+	return a.relax
+}
+
+func (a *Attributes) SetRelax(relax api.RelaxType) {
+	a.relax = relax
+}
+
+func (a *Attributes) Compress() bool {
+	return a.compress
+}
+
+func (a *Attributes) SetCompress(compress bool) {
+	a.compress = compress
+}
+
+func (a Attributes) String() string {
+	return fmt.Sprintf("[alignTo %d, global %v, access %s, %s, compress %v]", a.alignment, a.global, a.access, a.relax, a.compress)
+}
@@ -0,0 +1,224 @@
+package statements
+
+import (
+	"fmt"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// ---------------------------------------------------
+// "code" section statement
+// ---------------------------------------------------
+type CodeSectionStatement struct {
+	Statement
+
+	name       api.IToken
+	attributes api.IAttributes
+	// Instructions, labels and "use" statements in source order
+	statements []api.IStatement
+}
+
+func NewCodeSectionStatement(name api.IToken, attributes api.IAttributes, statements []api.IStatement) api.IStatement {
+	o := new(CodeSectionStatement)
+	o.name = name
+	o.attributes = attributes
+	o.statements = statements
+	return o
+}
+
+func (s *CodeSectionStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitCodeSectionStatement(s)
+}
+
+func (s *CodeSectionStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s *CodeSectionStatement) Attributes() api.IAttributes {
+	return s.attributes
+}
+
+func (s *CodeSectionStatement) Statements() []api.IStatement {
+	return s.statements
+}
+
+func (s CodeSectionStatement) String() string {
+	return "CodeSectionStatement " + s.name.Lexeme()
+}
+
+// ---------------------------------------------------
+// Instruction statement
+// ---------------------------------------------------
+type InstructionStatement struct {
+	Statement
+
+	mnemonic api.IToken
+	operands []api.IExpression
+	text     string
+}
+
+func NewInstructionStatement(mnemonic api.IToken, operands []api.IExpression, text string) api.IStatement {
+	o := new(InstructionStatement)
+	o.mnemonic = mnemonic
+	o.operands = operands
+	o.text = text
+	return o
+}
+
+func (s *InstructionStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitInstructionStatement(s)
+}
+
+func (s *InstructionStatement) Mnemonic() api.IToken {
+	return s.mnemonic
+}
+
+func (s *InstructionStatement) Operands() []api.IExpression {
+	return s.operands
+}
+
+func (s *InstructionStatement) Text() string {
+	return s.text
+}
+
+func (s InstructionStatement) String() string {
+	return fmt.Sprintf("InstructionStatement '%s' line: [%d]", s.mnemonic.Lexeme(), s.mnemonic.Line())
+}
+
+// ---------------------------------------------------
+// Label statement, for example "loop:"
+// ---------------------------------------------------
+type LabelStatement struct {
+	Statement
+
+	name api.IToken
+}
+
+func NewLabelStatement(name api.IToken) api.IStatement {
+	o := new(LabelStatement)
+	o.name = name
+	return o
+}
+
+func (s *LabelStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitLabelStatement(s)
+}
+
+func (s *LabelStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s LabelStatement) String() string {
+	return "LabelStatement " + s.name.Lexeme()
+}
+
+// ---------------------------------------------------
+// "use" statement
+// ---------------------------------------------------
+type UseStatement struct {
+	Statement
+
+	keyword api.IToken
+	name    api.IToken
+}
+
+func NewUseStatement(keyword, name api.IToken) api.IStatement {
+	o := new(UseStatement)
+	o.keyword = keyword
+	o.name = name
+	return o
+}
+
+func (s *UseStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitUseStatement(s)
+}
+
+func (s *UseStatement) Keyword() api.IToken {
+	return s.keyword
+}
+
+func (s *UseStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s UseStatement) String() string {
+	return "UseStatement " + s.name.Lexeme()
+}
+
+// ---------------------------------------------------
+// "macro" declaration
+// ---------------------------------------------------
+type MacroStatement struct {
+	Statement
+
+	name   api.IToken
+	params []api.IToken
+	body   []api.IStatement
+}
+
+func NewMacroStatement(name api.IToken, params []api.IToken, body []api.IStatement) api.IStatement {
+	o := new(MacroStatement)
+	o.name = name
+	o.params = params
+	o.body = body
+	return o
+}
+
+func (s *MacroStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitMacroStatement(s)
+}
+
+func (s *MacroStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s *MacroStatement) Parameters() []api.IToken {
+	return s.params
+}
+
+func (s *MacroStatement) Body() []api.IStatement {
+	return s.body
+}
+
+func (s MacroStatement) String() string {
+	return "MacroStatement " + s.name.Lexeme()
+}
+
+// ---------------------------------------------------
+// Macro invocation, for example "push(s0)"
+// ---------------------------------------------------
+type MacroInvocationStatement struct {
+	Statement
+
+	// The macro's name as a variable expression
+	macro     api.IExpression
+	arguments []api.IExpression
+}
+
+func NewMacroInvocationStatement(macro api.IExpression, arguments []api.IExpression) api.IStatement {
+	o := new(MacroInvocationStatement)
+	o.macro = macro
+	o.arguments = arguments
+	return o
+}
+
+func (s *MacroInvocationStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitMacroInvocationStatement(s)
+}
+
+func (s *MacroInvocationStatement) Expression() api.IExpression {
+	return s.macro
+}
+
+func (s *MacroInvocationStatement) Name() api.IToken {
+	return s.macro.Name()
+}
+
+// The arguments, unevaluated
+func (s *MacroInvocationStatement) Operands() []api.IExpression {
+	return s.arguments
+}
+
+func (s MacroInvocationStatement) String() string {
+	return fmt.Sprintf("MacroInvocationStatement '%s' line: [%d]", s.macro.Name().Lexeme(), s.macro.Name().Line())
+}
@@ -0,0 +1,161 @@
+package statements
+
+import (
+	"fmt"
+
+	"github.com/wdevore/RISCV-Meta-Assembler/src/api"
+)
+
+// ---------------------------------------------------
+// "data" section statement
+// ---------------------------------------------------
+type DataSectionStatement struct {
+	Statement
+
+	name       api.IToken
+	attributes api.IAttributes
+	elements   []api.IStatement
+}
+
+func NewDataSectionStatement(name api.IToken, attributes api.IAttributes, elements []api.IStatement) api.IStatement {
+	o := new(DataSectionStatement)
+	o.name = name
+	o.attributes = attributes
+	o.elements = elements
+	return o
+}
+
+func (s *DataSectionStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitDataSectionStatement(s)
+}
+
+func (s *DataSectionStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s *DataSectionStatement) Attributes() api.IAttributes {
+	return s.attributes
+}
+
+func (s *DataSectionStatement) Statements() []api.IStatement {
+	return s.elements
+}
+
+func (s DataSectionStatement) String() string {
+	return "DataSectionStatement " + s.name.Lexeme()
+}
+
+// ---------------------------------------------------
+// Data element, for example "half [0xff01, 0xab02]"
+// ---------------------------------------------------
+type DataElementStatement struct {
+	Statement
+
+	// The element type: "string", "char", "byte", "half", "word", "int"
+	// or the name of a struct
+	keyword api.IToken
+	// The struct type as a variable expression, nil for the others
+	structType api.IExpression
+	// Optional
+	name   api.IToken
+	global bool
+	// Size in bytes of a single value
+	size         int
+	initializers []api.IExpression
+	// The fields the initializers are for, struct elements only
+	fields []api.IToken
+}
+
+func NewDataElementStatement(keyword, name api.IToken, global bool, size int, initializers []api.IExpression) api.IStatement {
+	o := new(DataElementStatement)
+	o.keyword = keyword
+	o.name = name
+	o.global = global
+	o.size = size
+	o.initializers = initializers
+	return o
+}
+
+// A struct element, for example "Point origin { x = 0, y = 5 }". The
+// size comes from the struct. Struct fields are also struct elements,
+// without a name or fields.
+func NewStructElementStatement(structType api.IExpression, name api.IToken, global bool, fields []api.IToken, initializers []api.IExpression) api.IStatement {
+	o := new(DataElementStatement)
+	o.keyword = structType.Name()
+	o.structType = structType
+	o.name = name
+	o.global = global
+	o.fields = fields
+	o.initializers = initializers
+	return o
+}
+
+func (s *DataElementStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitDataElementStatement(s)
+}
+
+func (s *DataElementStatement) Keyword() api.IToken {
+	return s.keyword
+}
+
+func (s *DataElementStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s *DataElementStatement) Global() bool {
+	return s.global
+}
+
+func (s *DataElementStatement) ElementSize() int {
+	return s.size
+}
+
+func (s *DataElementStatement) Initializers() []api.IExpression {
+	return s.initializers
+}
+
+func (s *DataElementStatement) Expression() api.IExpression {
+	return s.structType
+}
+
+func (s *DataElementStatement) Names() []api.IToken {
+	return s.fields
+}
+
+func (s DataElementStatement) String() string {
+	return fmt.Sprintf("DataElementStatement '%s' line: [%d]", s.keyword.Lexeme(), s.keyword.Line())
+}
+
+// ---------------------------------------------------
+// "struct" declaration, for example "struct Point { word x; word y; }"
+// ---------------------------------------------------
+type StructStatement struct {
+	Statement
+
+	name api.IToken
+	// Data elements without values, one per field
+	fields []api.IStatement
+}
+
+func NewStructStatement(name api.IToken, fields []api.IStatement) api.IStatement {
+	o := new(StructStatement)
+	o.name = name
+	o.fields = fields
+	return o
+}
+
+func (s *StructStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitStructStatement(s)
+}
+
+func (s *StructStatement) Name() api.IToken {
+	return s.name
+}
+
+func (s *StructStatement) Statements() []api.IStatement {
+	return s.fields
+}
+
+func (s StructStatement) String() string {
+	return "StructStatement " + s.name.Lexeme()
+}
@@ -61,6 +61,42 @@ func (s *Statement) Value() api.IExpression {
 	return nil
 }
 
+func (s *Statement) Attributes() api.IAttributes {
+	return nil
+}
+
+func (s *Statement) Names() []api.IToken {
+	return nil
+}
+
+func (s *Statement) Paths() []api.IToken {
+	return nil
+}
+
+func (s *Statement) Global() bool {
+	return false
+}
+
+func (s *Statement) ElementSize() int {
+	return 0
+}
+
+func (s *Statement) Initializers() []api.IExpression {
+	return nil
+}
+
+func (s *Statement) Mnemonic() api.IToken {
+	return nil
+}
+
+func (s *Statement) Operands() []api.IExpression {
+	return nil
+}
+
+func (s *Statement) Text() string {
+	return ""
+}
+
 func (s Statement) String() string {
 	return ""
 }
@@ -371,3 +407,131 @@ func (s *ReturnStatement) StmtType() api.StatementType {
 func (s ReturnStatement) String() string {
 	return "ReturnStatement"
 }
+
+// ---------------------------------------------------
+// "const" block statement
+// ---------------------------------------------------
+type ConstStatement struct {
+	Statement
+
+	keyword      api.IToken
+	names        []api.IToken
+	initializers []api.IExpression
+}
+
+func NewConstStatement(keyword api.IToken, names []api.IToken, initializers []api.IExpression) api.IStatement {
+	o := new(ConstStatement)
+	o.keyword = keyword
+	o.names = names
+	o.initializers = initializers
+	return o
+}
+
+func (s *ConstStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitConstStatement(s)
+}
+
+func (s *ConstStatement) Keyword() api.IToken {
+	return s.keyword
+}
+
+// Names and Initializers are parallel
+func (s *ConstStatement) Names() []api.IToken {
+	return s.names
+}
+
+func (s *ConstStatement) Initializers() []api.IExpression {
+	return s.initializers
+}
+
+func (s ConstStatement) String() string {
+	return fmt.Sprintf("ConstStatement line: [%d]", s.keyword.Line())
+}
+
+// ---------------------------------------------------
+// "import" statement
+// ---------------------------------------------------
+type ImportStatement struct {
+	Statement
+
+	keyword api.IToken
+	paths   []api.IToken
+	aliases []api.IToken
+}
+
+func NewImportStatement(keyword api.IToken, paths, aliases []api.IToken) api.IStatement {
+	o := new(ImportStatement)
+	o.keyword = keyword
+	o.paths = paths
+	o.aliases = aliases
+	return o
+}
+
+func (s *ImportStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitImportStatement(s)
+}
+
+func (s *ImportStatement) Keyword() api.IToken {
+	return s.keyword
+}
+
+// Paths and Names are parallel
+func (s *ImportStatement) Paths() []api.IToken {
+	return s.paths
+}
+
+func (s *ImportStatement) Names() []api.IToken {
+	return s.aliases
+}
+
+func (s ImportStatement) String() string {
+	return fmt.Sprintf("ImportStatement line: [%d]", s.keyword.Line())
+}
+
+// ---------------------------------------------------
+// "enum" or "flags" declaration
+// ---------------------------------------------------
+type EnumStatement struct {
+	Statement
+
+	// "enum" or "flags"
+	keyword api.IToken
+	name    api.IToken
+	members []api.IToken
+	// nil where a member takes the value after the previous one
+	initializers []api.IExpression
+}
+
+func NewEnumStatement(keyword, name api.IToken, members []api.IToken, initializers []api.IExpression) api.IStatement {
+	o := new(EnumStatement)
+	o.keyword = keyword
+	o.name = name
+	o.members = members
+	o.initializers = initializers
+	return o
+}
+
+func (s *EnumStatement) Accept(visitor api.IVisitorStatement) (err api.IRuntimeError) {
+	return visitor.VisitEnumStatement(s)
+}
+
+func (s *EnumStatement) Keyword() api.IToken {
+	return s.keyword
+}
+
+func (s *EnumStatement) Name() api.IToken {
+	return s.name
+}
+
+// Names and Initializers are parallel
+func (s *EnumStatement) Names() []api.IToken {
+	return s.members
+}
+
+func (s *EnumStatement) Initializers() []api.IExpression {
+	return s.initializers
+}
+
+func (s EnumStatement) String() string {
+	return fmt.Sprintf("EnumStatement '%s' line: [%d]", s.name.Lexeme(), s.name.Line())
+}